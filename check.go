@@ -0,0 +1,43 @@
+package cmdargs
+
+import (
+  "errors"
+)
+
+// CheckFunc is a validation function that is invoked by Parameter.Evaluate and Parameter.Parse after all command
+// line arguments have been evaluated successfully. r provides the evaluated arguments.
+//
+// Returned errors are wrapped in an *EvalError of kind ValidationFailed, unless they already are of type *EvalError.
+type CheckFunc func(r *Result) error
+
+// AddCheck registers a validation function that can be used to implement rules spanning multiple options, e.g.
+// "--min must not be greater than --max".
+//
+// Validation functions are invoked in the order they were registered. Evaluate invokes all of them, even if one
+// of them fails, and returns the collected errors as a single *EvalError or an ErrorList.
+func (param *Parameter) AddCheck(fn CheckFunc) {
+  if fn != nil {
+    param.checks = append(param.checks, fn)
+  }
+}
+
+
+// Used internally. Invokes all registered validation functions and returns the collected errors.
+func (r *Result) runChecks() error {
+  var list ErrorList
+  for _, fn := range r.param.checks {
+    if err := fn(r); err != nil {
+      var e *EvalError
+      if !errors.As(err, &e) {
+        e = &EvalError{Kind: ValidationFailed, Index: -1, Position: -1, Err: err}
+      }
+      list = append(list, e)
+    }
+  }
+
+  switch len(list) {
+    case 0:   return nil
+    case 1:   return list[0]
+    default:  return list
+  }
+}
@@ -6,22 +6,43 @@ package cmdargs
 import (
   "fmt"
   "errors"
-  "path/filepath"
+  "io"
+  "os"
   "strings"
 )
 
 // Definition for a single parameter
 type paramType struct {
-  name      string      // Normalized long name of the parameter (i.e. without prefix)
-  numArgs   int         // Expected number of arguments
+  name        string            // Normalized long name of the parameter (i.e. without prefix)
+  aliases     []string          // Normalized alternate names of the parameter in registration order
+  taken       []string          // Normalized alternate names that were taken over by other parameters
+  numArgs     int               // Expected number of arguments
+  types       []ValueType       // Expected types of the option arguments
+  validators  []validatorEntry  // Validators for the option arguments
+  desc        string            // Description of the parameter
+  metavars    []string          // Placeholder names of the option arguments
+  group       string            // Name of the section in the usage output
+  defValue    []string          // Default values of the option arguments
+  kind        int               // Special parameter type, e.g. help or version
+  env         string            // Name of the bound environment variable
+  hint        CompletionHint    // Completion hint for the option arguments
+  completer   CompleteFunc      // Provides completion candidates for the option arguments
+  action      ActionFunc        // Invoked for every occurrence of the option
 }
 
 // Storage for a single argument
 type optionType struct {
   name      string      // Normalized long parameter name
   value     GenericList // List of option arguments
+  alias     string      // Normalized option name or alias as specified at the command line
+  pos       int         // Position in the command line arguments list, or -1 for environment variables
+  raw       []string    // Option and separate option arguments as specified at the command line
+  attached  bool        // Indicates whether the first option argument is attached by an equal sign
 }
 
+// ActionFunc is invoked by Parameter.Evaluate for every occurrence of an option as soon as it has been parsed.
+type ActionFunc func(arg Argument) error
+
 // Defines a slice of Generic datatypes.
 type GenericList []Generic
 
@@ -31,31 +52,66 @@ type paramMap map[string]*paramType
 // List of options
 type optionList []*optionType
 
-type Parameter struct {
-  aliases     paramMap    // map for parameter/alias names to parameter definitions
+// Documentation for a single file
+type fileType struct {
+  path      string
+  desc      string
+}
 
-  options     optionList  // Options are listed sequentially by their appearance in the command line arguments list
-  extra       GenericList // Remaining list of unparsed command line arguments (e.g. file names, etc.)
-  self        string      // Contains the application name (args[0]), unless it is identified as an option.
+// Documentation for a single usage example
+type exampleType struct {
+  cmdLine   string
+  desc      string
+}
+
+type Parameter struct {
+  aliases     paramMap        // map for parameter/alias names to parameter definitions
+  defs        []*paramType    // Parameter definitions in registration order
+  checks      []CheckFunc     // Validation functions that are invoked after evaluation
+  progName    string          // Application name for the usage output
+  progDesc    string          // Application description for the usage output
+  width       int             // Maximum line width of the usage output, or 0 to autodetect
+  version     string          // Version string of the application
+  out         io.Writer       // Output for automatically handled options
+  files       []fileType      // Files used by the application for documentation purposes
+  examples    []exampleType   // Usage examples for documentation purposes
+  commands    []*commandType  // Subcommands in registration order
+  parent      *Parameter      // Parent command, if this is a subcommand
+  cmdName     string          // Name of the subcommand, if this is a subcommand
+  dynamic     bool            // Indicates whether dynamic completion is enabled
+  positionals []*positionalType // Named positional arguments in registration order
+
+  result      *Result         // Result of the last call to Evaluate
 }
 
 // Argument structure contains information about a single argument.
 type Argument struct {
-  // Index is the absolute option index as specified at the command line.
+  // Index is the index of the option in the list of evaluated options. See Position for the index in the command
+  // line arguments list.
   Index     int
   // Name indicates the normalized long name of the option, i.e. without leading hyphens.
   Name      string
   // Arguments stores arguments needed by this option. It can be empty, but is never nil.
   Arguments GenericList
+  // Alias is the normalized option name or alias that was specified at the command line, e.g. "T" for "-T".
+  Alias     string
+  // Position is the index of the option in the command line arguments list, or -1 if the option was evaluated from
+  // an environment variable.
+  Position  int
+  // Token contains the option as specified at the command line, e.g. "--num-threads=5", or the content of the
+  // environment variable.
+  Token     string
+  // Attached indicates whether the first option argument was attached to the option by an equal sign.
+  Attached  bool
+
+  slots     []string    // Placeholder names of the option arguments
+  raw       []string    // Option and separate option arguments as specified at the command line
 }
 
 
 // Create creates an empty Parameter structure
 func Create() *Parameter {
-  p := Parameter { aliases: make(paramMap),
-                   options: make(optionList, 0),
-                   extra: make(GenericList, 0),
-                   self: "" }
+  p := Parameter { aliases: make(paramMap) }
   return &p
 }
 
@@ -70,32 +126,103 @@ func Create() *Parameter {
 // By convention, long option names start with two hyphens and single character options start with a single hyphen.
 // Parameter names and aliases are stored internally case-sensitive and with their prefix stripped.
 // For example, "--A" and "-A" are treated as identical, but "-A" and "-a" are not.
+//
+// Existing names and aliases of other parameters are reassigned. Use DefineParameter to reject conflicts, or Validate
+// to report them afterwards.
 func (param *Parameter) AddParameter(name string, aliases []string, numArgs int) {
   name = getOptionName(name)
   if len(name) == 0 { return }
-  if aliases != nil {
-    for i, alias := range aliases {
-      aliases[i] = getOptionName(alias)
-    }
-  }
+  aliases = getOptionNames(aliases)
   if numArgs < 0 { numArgs = 0 }
 
   p, ok := param.aliases[name]
   if !ok {
     p = &paramType{name: name, numArgs: 0}
+    param.defs = append(param.defs, p)
   }
   p.numArgs = numArgs
 
   param.aliases[name] = p
   if aliases != nil {
     for _, a := range aliases {
-      if len(a) > 0 {
+      if len(a) > 0 && a != p.name {
+        if old, ok := param.aliases[a]; ok && old != p {
+          // remembering the conflict for Validate
+          if old.hasAlias(a) { old.taken = append(old.taken, a) }
+          old.removeAlias(a)
+        }
+        p.taken = removeName(p.taken, a)
+        if !p.hasAlias(a) {
+          p.aliases = append(p.aliases, a)
+        }
         param.aliases[a] = p
       }
     }
   }
 }
 
+// SetParameterTypes declares the expected types of the arguments of the parameter specified by "name".
+//
+// types are assigned to the option arguments in the given order. Arguments without declared type are treated as
+// TypeString. Excess types are ignored.
+//
+// Evaluate rejects option arguments that cannot be converted to the declared type. Arguments are stored in converted
+// form, so that the respective conversion functions of the Generic interface never fail.
+//
+// Returns whether a parameter definition of given name exists.
+func (param *Parameter) SetParameterTypes(name string, types ...ValueType) bool {
+  p, ok := param.aliases[getOptionName(name)]
+  if ok {
+    p.types = append([]ValueType(nil), types...)
+  }
+  return ok
+}
+
+// SetParameterEnv binds the parameter specified by "name" to the environment variable of given name. Specify an empty
+// string to remove the binding.
+//
+// If the option is not specified at the command line, Evaluate adds it from the environment variable if available.
+// Options without arguments are added if the variable contains a boolean "true" value. Option arguments are
+// separated by whitespace.
+//
+// Returns whether a parameter definition of given name exists.
+func (param *Parameter) SetParameterEnv(name string, env string) bool {
+  p, ok := param.aliases[getOptionName(name)]
+  if ok {
+    p.env = env
+  }
+  return ok
+}
+
+// SetParameterAction defines a function that is invoked by Evaluate for every occurrence of the parameter specified
+// by "name", in the order of the command line arguments. Options that are evaluated from environment variables are
+// passed after all command line options. Specify nil to remove the function.
+//
+// The function receives the option in the same form as returned by GetArgAt, after conversion and validation of the
+// option arguments. Returning an error stops the evaluation. The error is wrapped in an *EvalError of kind
+// ActionFailed.
+//
+// Returns whether a parameter definition of given name exists.
+func (param *Parameter) SetParameterAction(name string, fn ActionFunc) bool {
+  p, ok := param.aliases[getOptionName(name)]
+  if ok {
+    p.action = fn
+  }
+  return ok
+}
+
+// AddFile documents a file used by the application, e.g. a configuration file. Files are listed by generated
+// documentation such as WriteManPage.
+func (param *Parameter) AddFile(path string, desc string) {
+  param.files = append(param.files, fileType{path: path, desc: desc})
+}
+
+// AddExample documents a usage example. cmdLine contains the full command line, including the application name.
+// Examples are listed by generated documentation such as WriteManPage or WriteMarkdown.
+func (param *Parameter) AddExample(cmdLine string, desc string) {
+  param.examples = append(param.examples, exampleType{cmdLine: cmdLine, desc: desc})
+}
+
 // RemoveParameter removes the parameter of given name. Returns whether there was a parameter definition that could
 // be removed.
 func (param *Parameter) RemoveParameter(name string) bool {
@@ -109,6 +236,61 @@ func (param *Parameter) RemoveParameter(name string) bool {
         delete(param.aliases, alias)
       }
     }
+    for i, def := range param.defs {
+      if def == p {
+        param.defs = append(param.defs[:i], param.defs[i+1:]...)
+        break
+      }
+    }
+  }
+  return ok
+}
+
+// RemoveParameterAlias removes the given alias from the parameter definition it refers to. Primary names can't be
+// removed. Returns whether there was an alias that could be removed.
+func (param *Parameter) RemoveParameterAlias(alias string) bool {
+  alias = getOptionName(alias)
+  p, ok := param.aliases[alias]
+  if !ok || p.name == alias { return false }
+  p.removeAlias(alias)
+  delete(param.aliases, alias)
+  return true
+}
+
+// RenameParameter changes the primary name of the parameter specified by "name" to newName. The former primary name
+// no longer refers to the parameter. If newName is an alias of the parameter, it is removed from the aliases.
+// All other settings are retained, and typed option handles follow the renamed parameter. Evaluated arguments are
+// not affected, so Evaluate should be called again.
+//
+// Returns a *DefinitionError if the parameter doesn't exist, or if newName is invalid as described for
+// DefineParameter or refers to another parameter.
+func (param *Parameter) RenameParameter(name string, newName string) error {
+  name, newName = getOptionName(name), getOptionName(newName)
+  p, ok := param.aliases[name]
+  if !ok { return &DefinitionError{Name: name, Err: ErrUnknownParameter} }
+  if err := checkOptionName(newName); err != nil { return &DefinitionError{Name: p.name, Alias: newName, Err: err} }
+  if other, ok := param.aliases[newName]; ok && other != p {
+    return &DefinitionError{Name: p.name, Alias: newName, Err: ErrNameConflict}
+  }
+
+  // the former primary name may have been taken over by an alias of another parameter
+  if param.aliases[p.name] == p { delete(param.aliases, p.name) }
+  p.removeAlias(newName)
+  p.name = newName
+  param.aliases[newName] = p
+  return nil
+}
+
+// SetParameterNumArgs changes the number of additional arguments belonging to the parameter specified by "name".
+// Other settings of the option arguments, such as types or placeholder names, are not adjusted. Use Validate to
+// detect settings that refer to more option arguments than expected.
+//
+// Returns whether a parameter definition of given name exists.
+func (param *Parameter) SetParameterNumArgs(name string, numArgs int) bool {
+  p, ok := param.aliases[getOptionName(name)]
+  if ok {
+    if numArgs < 0 { numArgs = 0 }
+    p.numArgs = numArgs
   }
   return ok
 }
@@ -118,94 +300,170 @@ func (param *Parameter) RemoveParameter(name string) bool {
 //
 // Parameter evaluation stops at the first occurence of a non-parameter string.
 // Remaining entries will be stored as an unparsed list of extra arguments. First entry will be stored as application
-// name, unless it is identified as an option.
+// name, unless it is identified as an option. If the first non-parameter string matches a subcommand added by
+// AddCommand, the remaining entries are evaluated by the subcommand instead.
+//
+// Options that are not specified at the command line are evaluated from the environment variables bound by
+// SetParameterEnv. Extra arguments are assigned to the positional arguments defined by AddPositional.
 //
-// Returns an error if a parameter is found that doesn't match any parameter definitions added by AddParameter.
+// Returns an error of type *EvalError if a parameter is found that doesn't match any parameter definitions added by
+// AddParameter, if option arguments are missing, don't match the declared types or are rejected by validators, or if
+// extra arguments don't match the positional argument definitions.
+// Errors of validation functions registered by AddCheck are returned as *EvalError or ErrorList.
+// Errors of action functions registered by SetParameterAction are returned as *EvalError of kind ActionFailed.
+// Returns ErrHelp or ErrVersion if options registered by AddHelpParameter or AddVersionParameter were specified,
+// and ErrComplete if dynamic completion was requested. See EnableCompletion for details.
+//
+// Evaluate stores the Result returned by Parse in the Parameter structure and in the Parameter structures of
+// evaluated subcommands, where it is accessed by the argument functions. Use Parse to evaluate command line
+// arguments concurrently.
 func (param *Parameter) Evaluate(args []string) error {
+  if args == nil || len(args) == 0 { return nil }
+
+  r, err := param.Parse(args)
+  r.store()
+  return err
+}
+
+// Parse parses and evaluates the arguments in the given string array as described for Evaluate and returns the
+// evaluated arguments as a Result.
+//
+// Parse doesn't modify the Parameter structure. It is safe for concurrent use, provided that the parameter
+// definitions are not modified and Evaluate is not called at the same time.
+//
+// The returned Result is never nil. It contains the arguments evaluated so far if an error is returned.
+func (param *Parameter) Parse(args []string) (*Result, error) {
+  return param.parse(args, 0, nil)
+}
+
+// EvaluateString splits the given command line into arguments as described for SplitCommandLine and evaluates them
+// as described for Evaluate. The first argument is treated as application name, unless it is identified as an option.
+func (param *Parameter) EvaluateString(line string) error {
+  args, err := SplitCommandLine(line)
+  if err != nil { return err }
+  return param.Evaluate(args)
+}
+
+// ParseString behaves just like EvaluateString, but returns the evaluated arguments as a Result instead of storing
+// them in the Parameter structure. See Parse for details.
+func (param *Parameter) ParseString(line string) (*Result, error) {
+  args, err := SplitCommandLine(line)
+  if err != nil { return newResult(param), err }
+  return param.Parse(args)
+}
+
+// SplitCommandLine splits the given command line into arguments. Arguments are separated by whitespace. Single and
+// double quotes and backslash escapes are handled as in POSIX shells. Variables, wildcards and other shell expansions
+// are not supported.
+//
+// Returns an error if the command line ends within a quoted string or escape sequence.
+func SplitCommandLine(line string) ([]string, error) {
+  args, open := splitCommandLine(line)
+  if open { return args, errors.New("Unterminated quoted string or escape sequence in command line") }
+  return args, nil
+}
+
+// Used internally. Evaluates the arguments in args, beginning at argIdx. Positions refer to the whole args list.
+// parent is the result of the parent command if param is a subcommand, or nil otherwise. Subcommands share the
+// copy of args made by the parent command.
+func (param *Parameter) parse(args []string, argIdx int, parent *Result) (*Result, error) {
   var err error = nil
-  if args == nil || len(args) == 0 { return err }
+  r := newResult(param)
+  r.parent = parent
+  if argIdx >= len(args) { return r, err }
+  if parent == nil {
+    // raw tokens refer to a copy, so that the Result is not affected if the caller modifies args
+    args = append(make([]string, 0, len(args)), args...)
+  }
+  r.reserve(args[argIdx:])
 
-  param.reset()
-  argIdx := 0
+  // hidden entry point for dynamic completion
+  if param.dynamic && len(args) > argIdx + 1 && args[argIdx + 1] == completeCommand && !isOption(args[argIdx]) {
+    if err = param.writeCompletion(param.getOutput(), args[argIdx + 2:]); err != nil { return r, err }
+    return r, ErrComplete
+  }
+  if param.dynamic && param.parent == nil {
+    if line, ok := getCompletionLine(args[argIdx:]); ok {
+      if err = param.writeCompletionLine(param.getOutput(), line); err != nil { return r, err }
+      return r, ErrComplete
+    }
+  }
 
   // initializing "self"
   if !isOption(args[argIdx]) {
-    param.self = args[argIdx]
+    r.self = args[argIdx]
     argIdx++
   }
 
   // parsing options
-  for argIdx < len(args) {
-    var name string
-    var arg *optionType
-    oldIdx := argIdx
-    name, arg, argIdx, err = param.evalArg(args, argIdx)
-    if err != nil { return err }
-    if name == "" { break }     // remaining entries are not options
-    if argIdx == oldIdx { return errors.New("Fatal: Deadlock while evaluating parameters") }  // should never happen!
-    name = getOptionName(name)  // normalizing option name
-    param.options = append(param.options, arg)
+  if argIdx, err = r.evalOptions(args, argIdx); err != nil { return r, err }
+
+  // initializing subcommand or extra arguments
+  if argIdx < len(args) {
+    r.command = param.getCommand(args[argIdx])
   }
+  if r.command == nil {
+    r.extraPos, r.rawExtra = argIdx, args[argIdx:]
+    r.extra = r.allocValues(len(args) - argIdx)
+    for idx := argIdx; idx < len(args); idx++ {
+      r.extra = append(r.extra, Generic(String(args[idx])))
+    }
+  }
+
+  if def := r.getSpecial(); def != nil { return r, param.handleSpecial(def, r) }
 
-  // initializing extra arguments
-  for idx := argIdx; idx < len(args); idx++ {
-    param.extra = append(param.extra, Generic(String(args[idx])))
+  if r.command == nil && len(param.positionals) > 0 {
+    if err = r.evalPositionals(argIdx); err != nil { return r, err }
   }
 
-  return err
+  if err = r.runChecks(); err != nil { return r, err }
+
+  if r.command != nil {
+    r.sub, err = r.command.param.parse(args, argIdx, r)
+  }
+  return r, err
 }
 
 
 // GetArgSelf returns the first argument of the argument list, unless it was identified as a regular option.
 // It is usually the application name.
 func (param *Parameter) GetArgSelf() string {
-  return param.self
+  return param.getResult().GetArgSelf()
 }
 
 
 // GetArgExtraLength returns the number of available extra arguments that were not evaluated as regular options.
 func (param *Parameter) GetArgExtraLength() int {
-  return len(param.extra)
+  return param.getResult().GetArgExtraLength()
 }
 
 // GetArgExtra returns the extra argument at the specified index.
 func (param *Parameter) GetArgExtra(index int) Generic {
-  if index < 0 || index > param.GetArgExtraLength() { return String("") }
-  return param.extra[index]
+  return param.getResult().GetArgExtra(index)
 }
 
 // GetExpandedArgExtra treats the given argument as a wildcard and expands it relative to current directory.
 //
 // Returns a list with all matching path strings (which may be empty if no match is found), or an empty list on error.
 func (param *Parameter) GetExpandedArgExtra(index int) []string {
-  retVal := make([]string, 0)
-  if index < 0 || index > param.GetArgExtraLength() { return retVal }
-
-  expanded, err := filepath.Glob(param.extra[index].ToString())
-  if err == nil {
-    retVal = append(retVal, expanded...)
-  }
-  return retVal
+  return param.getResult().GetExpandedArgExtra(index)
 }
 
 
 // GetArgLength returns the number of evaluated options.
 func (param *Parameter) GetArgLength() int {
-  return len(param.options)
+  return param.getResult().GetArgLength()
 }
 
 // GetArgExists returns whether the argument of given name has been evaluated by a previous
 // call to Evaluate. It considers option names and aliases.
 func (param *Parameter) GetArgExists(name string) bool {
-  name = param.getLongOptionName(name)
-  if len(name) > 0 {
-    for _, option := range param.options {
-      if option.name == name {
-        return true
-      }
-    }
-  }
-  return false
+  return param.getResult().GetArgExists(name)
+}
+
+// GetArgCount returns the number of instances of the option with the given name.
+func (param *Parameter) GetArgCount(name string) int {
+  return param.getResult().GetArgCount(name)
 }
 
 // GetArgIndex returns the index of the specified option in the command line options list.
@@ -218,35 +476,7 @@ func (param *Parameter) GetArgExists(name string) bool {
 // Returns a positive index when searching forward and a negative index when searching backwards. Both index variants
 // can be fed directly to the GetArgAt function to return the Argument structure of the given option.
 func (param *Parameter) GetArgIndex(name string, startIndex int) (pos int, exists bool) {
-  pos = startIndex
-  name = param.getLongOptionName(name)
-  if len(name) == 0 { return }
-
-  // initializing forward/backward search
-  reverse := startIndex < 0
-  var limit, inc int
-  if reverse {
-    startIndex = len(param.options) + startIndex
-    if startIndex < 0 { return }
-    limit = -1
-    inc = -1
-  } else {
-    if startIndex >= len(param.options) { return }
-    limit = len(param.options)
-    inc = 1
-  }
-
-  for pos = startIndex; pos != limit; pos += inc {
-    option := param.options[pos]
-    exists = (option.name == name)
-    if exists { break }
-  }
-
-  if reverse {
-    pos -= len(param.options)
-  }
-
-  return
+  return param.getResult().GetArgIndex(name, startIndex)
 }
 
 // GetArgAt returns the option at the specified index as an Argument structure.
@@ -257,108 +487,334 @@ func (param *Parameter) GetArgIndex(name string, startIndex int) (pos int, exist
 //
 // The second return value indicates success of the operation.
 func (param *Parameter) GetArgAt(index int) (arg Argument, err error) {
-  if index < 0 {
-    index = len(param.options) + index
-  }
+  return param.getResult().GetArgAt(index)
+}
 
-  if index < 0 || index >= len(param.options) {
-    err = errors.New("Parameter.GetArgNameByPosition: Invalid position")
-    return
-  }
+// GetFirstArgOf returns the first instance of the option with the given name.
+func (param *Parameter) GetFirstArgOf(name string) (arg Argument, exists bool) {
+  return param.getResult().GetFirstArgOf(name)
+}
+
+// GetLastArgOf returns the last instance of the option with the given name.
+func (param *Parameter) GetLastArgOf(name string) (arg Argument, exists bool) {
+  return param.getResult().GetLastArgOf(name)
+}
+
+// GetArgParam returns the option argument at the specified index of the last instance of the option with the
+// given name.
+//
+// The second return value indicates whether the option and the requested argument exist.
+func (param *Parameter) GetArgParam(name string, index int) (value Generic, exists bool) {
+  return param.getResult().GetArgParam(name, index)
+}
+
+// GetFirstArgParam returns the option argument at the specified index of the first instance of the option with the
+// given name.
+//
+// The second return value indicates whether the option and the requested argument exist.
+func (param *Parameter) GetFirstArgParam(name string, index int) (value Generic, exists bool) {
+  return param.getResult().GetFirstArgParam(name, index)
+}
+
+// GetArgSlot returns the option argument with the given placeholder name of the last instance of the option with
+// the given name. Placeholder names are defined by SetParameterMetavars and are compared case-insensitively.
+//
+// The second return value indicates whether the option and the requested argument exist.
+func (param *Parameter) GetArgSlot(name string, slot string) (value Generic, exists bool) {
+  return param.getResult().GetArgSlot(name, slot)
+}
 
-  option := param.options[index]
-  arg = Argument{Index: index, Name: option.name, Arguments: make(GenericList, len(option.value))}
-  copy(arg.Arguments, option.value)
+// GetArgParamDefault behaves just like GetArgParam, but returns def if the option or the requested argument does
+// not exist.
+func (param *Parameter) GetArgParamDefault(name string, index int, def Generic) Generic {
+  return param.getResult().GetArgParamDefault(name, index, def)
+}
+
+// GetArgParams returns the arguments of all instances of the option with the given name in the order they were
+// specified at the command line. Returns an empty list if the option doesn't exist.
+func (param *Parameter) GetArgParams(name string) GenericList {
+  return param.getResult().GetArgParams(name)
+}
 
+// GetArgValues returns the option arguments of the specified instance of the option with the given name without
+// copying them. Positive instance indices are relative to the first instance, negative indices are relative to the
+// position directly behind the last instance, i.e. -1 returns the last instance. The returned list must not be
+// modified.
+//
+// The second return value indicates whether the requested instance exists.
+func (param *Parameter) GetArgValues(name string, instance int) (values GenericList, exists bool) {
+  return param.getResult().GetArgValues(name, instance)
+}
+
+// GetArgExtraPosition returns the index of the extra argument at the specified index in the command line arguments
+// list evaluated by a previous call to Evaluate. Returns -1 if the extra argument doesn't exist.
+func (param *Parameter) GetArgExtraPosition(index int) int {
+  return param.getResult().GetArgExtraPosition(index)
+}
+
+// GetArgExtraToken returns the extra argument at the specified index as specified at the command line, i.e. before
+// conversion to the type of a positional argument. Returns an empty string if the extra argument doesn't exist.
+func (param *Parameter) GetArgExtraToken(index int) string {
+  return param.getResult().GetArgExtraToken(index)
+}
+
+// Visit invokes the given functions for the options and extra arguments evaluated by a previous call to Evaluate.
+// See Result.Visit for details.
+func (param *Parameter) Visit(option func(arg Argument) bool, extra func(name string, value Generic) bool) {
+  param.getResult().Visit(option, extra)
+}
+
+// Value returns the option argument with the given placeholder name. Placeholder names are defined by
+// Parameter.SetParameterMetavars and are compared case-insensitively.
+//
+// The second return value indicates whether the requested argument exists.
+func (arg Argument) Value(slot string) (value Generic, exists bool) {
+  for i, s := range arg.slots {
+    if strings.EqualFold(s, slot) && i < len(arg.Arguments) {
+      return arg.Arguments[i], true
+    }
+  }
   return
 }
 
-// GetFirstArgOf returns the first instance of the option with the given name.
-func (param *Parameter) GetFirstArgOf(name string) (arg Argument, exists bool) {
-  var idx int
-  idx, exists = param.GetArgIndex(name, 0)
-  if exists {
-    var err error
-    arg, err = param.GetArgAt(idx)
-    exists = (err == nil)
+
+// ValuePosition returns the index of the option argument at the specified index in the command line arguments list.
+// Returns -1 if the option argument doesn't exist or was evaluated from an environment variable.
+func (arg Argument) ValuePosition(index int) int {
+  if arg.Position < 0 || index < 0 || index >= len(arg.Arguments) { return -1 }
+  if arg.Attached { return arg.Position + index }
+  return arg.Position + 1 + index
+}
+
+// RawValue returns the option argument at the specified index as specified at the command line, i.e. before
+// conversion to the declared type.
+//
+// The second return value indicates whether the requested argument exists.
+func (arg Argument) RawValue(index int) (value string, exists bool) {
+  if index < 0 || index >= len(arg.Arguments) { return }
+  if arg.Attached {
+    if index == 0 {
+      _, value, exists = strings.Cut(arg.Token, "=")
+      return
+    }
+    index--
+  }
+  if index + 1 < len(arg.raw) {
+    value, exists = arg.raw[index + 1], true
   }
   return
 }
 
-// GetLastArgOf returns the last instance of the option with the given name.
-func (param *Parameter) GetLastArgOf(name string) (arg Argument, exists bool) {
-  var idx int
-  idx, exists = param.GetArgIndex(name, -1)
-  if exists {
-    var err error
-    arg, err = param.GetArgAt(idx)
-    exists = (err == nil)
+
+// Used internally. Returns whether the given normalized name is an alias of the parameter.
+func (def *paramType) hasAlias(alias string) bool {
+  for _, a := range def.aliases {
+    if a == alias { return true }
+  }
+  return false
+}
+
+// Used internally. Removes the given normalized name from the alias list of the parameter.
+func (def *paramType) removeAlias(alias string) {
+  def.aliases = removeName(def.aliases, alias)
+}
+
+// Used internally. Removes the first occurrence of the given name from the list and returns the updated list.
+func removeName(names []string, name string) []string {
+  for i, n := range names {
+    if n == name {
+      return append(names[:i], names[i+1:]...)
+    }
+  }
+  return names
+}
+
+// Used internally. Returns the option argument at the specified index.
+func (option *optionType) getValue(index int) (value Generic, exists bool) {
+  if index >= 0 && index < len(option.value) {
+    value = option.value[index]
+    exists = true
   }
   return
 }
 
+// Used internally. Returns the Result of the last call to Evaluate, or an empty Result if not available.
+func (param *Parameter) getResult() *Result {
+  if param.result != nil { return param.result }
+  return newResult(param)
+}
 
-// Used internally. Resets all Parameter fields that are related to argument evaluation to initial state.
-func (param *Parameter) reset() {
-  if len(param.options) != 0 {
-    param.options = make(optionList, 0)
+// Used internally. Makes r and the results of evaluated subcommands available by the argument functions of the
+// respective Parameter structures.
+func (r *Result) store() {
+  for ; r != nil; r = r.sub {
+    r.param.result = r
   }
-  if len(param.extra) != 0 {
-    param.extra = make(GenericList, 0)
+}
+
+// Used internally. Evaluates the options in args, beginning at argIdx, and the options bound to environment variables.
+// Returns the index of the first argument that is not an option.
+func (r *Result) evalOptions(args []string, argIdx int) (int, error) {
+  param := r.param
+  start := argIdx
+  for argIdx < len(args) {
+    oldIdx := argIdx
+    name, arg, newIdx, err := r.evalArg(args, argIdx)
+    if err != nil {
+      // help or version options take precedence over evaluation errors
+      if def := param.findSpecial(args[start:]); def != nil { return argIdx, param.handleSpecial(def, r) }
+      return argIdx, err
+    }
+    if name == "" { break }     // remaining entries are not options
+    // should never happen!
+    if newIdx == oldIdx { return argIdx, errors.New("Fatal: Deadlock while evaluating parameters") }
+    argIdx = newIdx
+    if err = r.runAction(r.addOption(arg), oldIdx, args[oldIdx]); err != nil {
+      if def := param.findSpecial(args[start:]); def != nil { return argIdx, param.handleSpecial(def, r) }
+      return argIdx, err
+    }
+  }
+
+  // evaluating options bound to environment variables
+  if err := r.evalEnv(); err != nil {
+    if def := param.findSpecial(args[start:]); def != nil { return argIdx, param.handleSpecial(def, r) }
+    return argIdx, err
+  }
+  return argIdx, nil
+}
+
+// Used internally. Returns the first evaluated help or version option, or nil if not available.
+func (r *Result) getSpecial() *paramType {
+  for _, option := range r.options {
+    if def, ok := r.param.aliases[option.name]; ok && def.kind != paramRegular {
+      return def
+    }
   }
-  param.self = ""
+  return nil
+}
+
+// Used internally. Adds options that are not specified at the command line from bound environment variables.
+func (r *Result) evalEnv() error {
+  for _, def := range r.param.defs {
+    if len(def.env) == 0 || r.GetArgExists(def.name) { continue }
+    value, ok := os.LookupEnv(def.env)
+    if !ok { continue }
+
+    if def.numArgs == 0 {
+      b, ok := String(value).Bool()
+      if !ok {
+        return &EvalError{Kind: InvalidValue, Option: def.name, Index: -1, Position: -1, Value: value,
+                          Err: fmt.Errorf("expected boolean in environment variable %s", def.env)}
+      }
+      if !b { continue }
+    }
+    arg := r.newOption(def.name, def.numArgs)
+    arg.alias, arg.pos, arg.raw = def.name, -1, []string{value}
+    if def.numArgs > 0 {
+      values := strings.Fields(value)
+      if len(values) < def.numArgs {
+        return &EvalError{Kind: MissingValue, Option: def.name, Slot: def.getMetavar(len(values)),
+                          Index: len(values), Position: -1, Value: value,
+                          Err: fmt.Errorf("environment variable %s: available=%d, need=%d",
+                                          def.env, len(values), def.numArgs)}
+      }
+      for _, v := range values[:def.numArgs] {
+        if err := def.addValue(arg, v, -1); err != nil { return err }
+      }
+      arg.raw = append(arg.raw, values[:def.numArgs]...)
+    }
+    if err := r.runAction(r.addOption(arg), -1, value); err != nil { return err }
+  }
+  return nil
+}
+
+// Used internally. Invokes the action function of the option at the specified index, if available.
+// pos and token indicate the position and the content of the option in the command line arguments list.
+func (r *Result) runAction(index int, pos int, token string) error {
+  option := r.options[index]
+  def, ok := r.param.aliases[option.name]
+  if !ok || def.action == nil { return nil }
+
+  arg, err := r.GetArgAt(index)
+  if err == nil {
+    err = def.action(arg)
+  }
+  if err != nil {
+    return &EvalError{Kind: ActionFailed, Option: def.name, Index: -1, Position: pos, Value: token, Err: err}
+  }
+  return nil
 }
 
 // Used internally. Attempts to parse the next available command line argument.
-func (param *Parameter) evalArg(args []string, index int) (name string, arg *optionType, newIdx int, err error) {
-  arg = &optionType{name: "", value: make(GenericList, 0) }
+func (r *Result) evalArg(args []string, index int) (name string, arg *optionType, newIdx int, err error) {
   newIdx = index
   if newIdx < 0 || newIdx >= len(args) { return }
 
   // remaining arguments are treated as non-options
   if !isOption(args[newIdx]) { return }
 
-  // parsing new option
-  args0 := strings.Split(args[newIdx], "=")
-  if len(args0) == 0 { return }
-  name = getOptionName(args0[0])
+  // parsing new option; subsequent equal signs are treated as part of the extra argument
+  s0, s1, hasValue := strings.Cut(args[newIdx], "=")
+  name = getOptionName(s0)
   newIdx++
 
-  def, ok := param.aliases[name]
-  if !ok { err = fmt.Errorf("Unrecognized option: \"--%s\" or \"-%s\"", name, name); return }
+  def, ok := r.param.aliases[name]
+  if !ok { err = &EvalError{Kind: UnrecognizedOption, Index: -1, Position: index, Value: name}; return }
 
+  // option name is shared with the parameter definition
+  arg = r.newOption(def.name, def.numArgs)
+  arg.alias, arg.pos = name, index
   numArgs := def.numArgs
 
   // option may contain extra argument, separated by equal sign
-  if numArgs > 0 && len(args0) > 1 {
-    s := args0[1]
-    for i := 2; i < len(args0); i++ {
-      // subsequent equal signs are treated as part of the extra argument
-      s += "="
-      s += args[i]
-    }
-    arg.value = append(arg.value, trimArg(s))
+  if numArgs > 0 && hasValue {
+    if err = def.addValue(arg, s1, index); err != nil { return }
+    arg.attached = true
     numArgs--
   }
 
   numRemaining := len(args) - newIdx
   if numRemaining < numArgs {
-    err = fmt.Errorf("Too few option arguments: available=%d, need=%d", numRemaining, numArgs);
+    idx := len(arg.value) + numRemaining
+    err = &EvalError{Kind: MissingValue, Option: def.name, Slot: def.getMetavar(idx), Index: idx,
+                     Position: index, Value: args[index],
+                     Err: fmt.Errorf("available=%d, need=%d", numRemaining, numArgs)}
     return
   }
 
   // parsing remaining option arguments
   for ; numArgs > 0; numArgs, newIdx = numArgs-1, newIdx+1 {
-    a := args[newIdx]
-    arg.value = append(arg.value, trimArg(a))
+    if err = def.addValue(arg, args[newIdx], newIdx); err != nil { return }
   }
+  arg.raw = args[index:newIdx]
 
-  name = param.getLongOptionName(name)  // always returns long option name
-  arg.name = name
+  name = def.name   // always returns long option name
 
   return
 }
 
+// Used internally. Adds the given option argument to the option after converting it to the declared type and
+// applying validators.
+// pos indicates the index of the argument in the command line arguments list.
+func (def *paramType) addValue(arg *optionType, s string, pos int) error {
+  v := trimArg(s)
+  if idx := len(arg.value); idx < len(def.types) {
+    cv, ok := def.types[idx].convert(v)
+    if !ok {
+      return &EvalError{Kind: InvalidValue, Option: def.name, Slot: def.getMetavar(idx), Index: idx, Position: pos,
+                        Value: v.ToString(), Err: fmt.Errorf("expected %s", def.types[idx])}
+    }
+    v = cv
+  }
+  if err := def.validate(len(arg.value), v); err != nil {
+    idx := len(arg.value)
+    return &EvalError{Kind: InvalidValue, Option: def.name, Slot: def.getMetavar(idx), Index: idx, Position: pos,
+                      Value: v.ToString(), Err: err}
+  }
+  arg.value = append(arg.value, v)
+  return nil
+}
+
 // Used internally. Removes spaces and double-quotes from arguments if needed.
 func trimArg(arg string) Generic {
   // strip double-quotes from arguments, but leave single quotes unchanged
@@ -386,6 +842,15 @@ func (param *Parameter) getLongOptionName(alias string) string {
   return retVal
 }
 
+// Used internally. Returns a new list with the prefixes stripped from the given option names.
+func getOptionNames(names []string) []string {
+  retVal := make([]string, len(names))
+  for i, name := range names {
+    retVal[i] = getOptionName(name)
+  }
+  return retVal
+}
+
 // Used internally. Strips prefix from option name.
 func getOptionName(name string) string {
   if len(name) >= 2 && name[:2] == "--" {
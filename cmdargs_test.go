@@ -0,0 +1,385 @@
+package cmdargs
+
+import (
+  "errors"
+  "fmt"
+  "os"
+  "strconv"
+  "strings"
+  "testing"
+)
+
+// Editing a parameter whose primary name has been taken over as alias by another parameter must not affect the
+// other parameter.
+func TestRenameParameterNameTakenOver(t *testing.T) {
+  param := Create()
+  param.AddParameter("foo", []string{"f"}, 0)
+  param.AddParameter("bar", []string{"foo"}, 0)
+
+  if err := param.RenameParameter("f", "baz"); err != nil {
+    t.Fatalf("RenameParameter: %v", err)
+  }
+  if d, ok := param.Lookup("foo"); !ok || d.Name() != "bar" {
+    t.Errorf("Lookup(\"foo\") = %v, %v; want bar", d, ok)
+  }
+  for _, name := range []string{"baz", "f"} {
+    if d, ok := param.Lookup(name); !ok || d.Name() != "baz" {
+      t.Errorf("Lookup(%q) = %v, %v; want baz", name, d, ok)
+    }
+  }
+  if err := param.Validate(); err != nil {
+    t.Errorf("Validate: %v", err)
+  }
+
+  if !param.RemoveParameterAlias("foo") {
+    t.Fatal("RemoveParameterAlias(\"foo\") failed")
+  }
+  if _, ok := param.Lookup("foo"); ok {
+    t.Error("Lookup(\"foo\") succeeded after alias removal")
+  }
+  if d, ok := param.Lookup("bar"); !ok || len(d.Aliases()) != 0 {
+    t.Errorf("Lookup(\"bar\") = %v, %v; want no aliases", d.Aliases(), ok)
+  }
+}
+
+// Nil validators must be ignored instead of failing during evaluation.
+func TestAddPositionalValidatorNil(t *testing.T) {
+  param := Create()
+  param.AddPositional("file", 1, 1)
+  if !param.AddPositionalValidator("file", nil) {
+    t.Fatal("AddPositionalValidator failed")
+  }
+  if err := param.Evaluate([]string{"app", "a.txt"}); err != nil {
+    t.Errorf("Evaluate: %v", err)
+  }
+}
+
+// Completion candidates returned by completion functions must be filtered by the partial argument.
+func TestCompleterPrefix(t *testing.T) {
+  param := Create()
+  param.AddParameter("color", nil, 1)
+  param.SetParameterCompleter("color", func(index int, prefix string) []Completion {
+    return []Completion{{Value: "red"}, {Value: "green"}, {Value: "grey"}}
+  })
+  candidates, _ := param.Complete([]string{"--color", "gr"})
+  if len(candidates) != 2 || candidates[0].Value != "green" || candidates[1].Value != "grey" {
+    t.Errorf("Complete = %v; want green, grey", candidates)
+  }
+}
+
+// Typed option arguments must be formatted as specified at the command line.
+func TestTypedValueFormat(t *testing.T) {
+  param := Create()
+  param.AddParameter("num", nil, 2)
+  param.SetParameterTypes("num", TypeInt, TypeFloat)
+  if err := param.Evaluate([]string{"app", "--num", "0x1f", "1.50"}); err != nil {
+    t.Fatalf("Evaluate: %v", err)
+  }
+  values, _ := param.GetArgValues("num", 0)
+  if s := fmt.Sprint(values[0], values[1]); s != "0x1f 1.50" {
+    t.Errorf("Sprint = %q; want \"0x1f 1.50\"", s)
+  }
+  if s := fmt.Sprintf("%v|%5s|%d|%.1f", values[0], values[0], values[0], values[1]); s != "0x1f| 0x1f|31|1.5" {
+    t.Errorf("Sprintf = %q", s)
+  }
+}
+
+// Number of "-D" options evaluated by the lookup benchmarks.
+const benchDefines = 5000
+
+// Used internally. Returns a parameter with evaluated lookup benchmark arguments.
+func benchLookupParam(tb testing.TB) *Parameter {
+  param := Create()
+  param.AddParameter("define", []string{"D"}, 1)
+  param.AddParameter("verbose", []string{"v"}, 0)
+  param.AddParameter("quiet", []string{"q"}, 0)
+  args := []string{"app", "--verbose"}
+  for i := 0; i < benchDefines; i++ {
+    args = append(args, "-D", "key" + strconv.Itoa(i) + "=value")
+  }
+  args = append(args, "-v")
+  if err := param.Evaluate(args); err != nil {
+    tb.Fatalf("Evaluate: %v", err)
+  }
+  return param
+}
+
+// Used internally. Linear scan reference implementation of GetArgExists.
+func linearArgExists(r *Result, name string) bool {
+  name = r.param.getLongOptionName(name)
+  for _, option := range r.options {
+    if option.name == name { return true }
+  }
+  return false
+}
+
+// Used internally. Linear scan reference implementation of GetArgIndex.
+func linearArgIndex(r *Result, name string, startIndex int) (pos int, exists bool) {
+  name = r.param.getLongOptionName(name)
+  if startIndex < 0 {
+    for pos = len(r.options) + startIndex; pos >= 0; pos-- {
+      if r.options[pos].name == name { return pos - len(r.options), true }
+    }
+  } else {
+    for pos = startIndex; pos < len(r.options); pos++ {
+      if r.options[pos].name == name { return pos, true }
+    }
+  }
+  return startIndex, false
+}
+
+// Used internally. Linear scan reference implementation of GetArgValues.
+func linearArgValues(r *Result, name string, instance int) (values GenericList, exists bool) {
+  name = r.param.getLongOptionName(name)
+  var positions []int
+  for pos, option := range r.options {
+    if option.name == name { positions = append(positions, pos) }
+  }
+  if instance < 0 { instance = len(positions) + instance }
+  if instance >= 0 && instance < len(positions) {
+    values, exists = r.options[positions[instance]].value, true
+  }
+  return
+}
+
+// Indexed lookups must return the same results as the linear scan reference implementations.
+func TestGetArgLookup(t *testing.T) {
+  param := benchLookupParam(t)
+  r := param.getResult()
+  for _, name := range []string{"D", "define", "v", "--verbose", "q", "unknown"} {
+    if got, want := param.GetArgExists(name), linearArgExists(r, name); got != want {
+      t.Errorf("GetArgExists(%q) = %v; want %v", name, got, want)
+    }
+    for _, start := range []int{0, 1, 2, benchDefines, benchDefines + 1, benchDefines + 2, -1, -2, -benchDefines} {
+      pos, ok := param.GetArgIndex(name, start)
+      wantPos, wantOk := linearArgIndex(r, name, start)
+      if pos != wantPos || ok != wantOk {
+        t.Errorf("GetArgIndex(%q, %d) = %d, %v; want %d, %v", name, start, pos, ok, wantPos, wantOk)
+      }
+    }
+    for _, instance := range []int{0, 1, benchDefines - 1, benchDefines, -1, -2, -benchDefines - 1} {
+      values, ok := param.GetArgValues(name, instance)
+      wantValues, wantOk := linearArgValues(r, name, instance)
+      if ok != wantOk || fmt.Sprint(values) != fmt.Sprint(wantValues) {
+        t.Errorf("GetArgValues(%q, %d) = %v, %v; want %v, %v", name, instance, values, ok, wantValues, wantOk)
+      }
+    }
+  }
+}
+
+func BenchmarkGetArgExists(b *testing.B) {
+  param := benchLookupParam(b)
+  r := param.getResult()
+  b.Run("indexed", func(b *testing.B) {
+    b.ReportAllocs()
+    for i := 0; i < b.N; i++ {
+      if !param.GetArgExists("v") || param.GetArgExists("q") { b.Fatal("unexpected result") }
+    }
+  })
+  b.Run("linear", func(b *testing.B) {
+    b.ReportAllocs()
+    for i := 0; i < b.N; i++ {
+      if !linearArgExists(r, "v") || linearArgExists(r, "q") { b.Fatal("unexpected result") }
+    }
+  })
+}
+
+func BenchmarkGetArgIndex(b *testing.B) {
+  param := benchLookupParam(b)
+  r := param.getResult()
+  b.Run("indexed", func(b *testing.B) {
+    b.ReportAllocs()
+    for i := 0; i < b.N; i++ {
+      if _, ok := param.GetArgIndex("v", 1); !ok { b.Fatal("unexpected result") }
+    }
+  })
+  b.Run("linear", func(b *testing.B) {
+    b.ReportAllocs()
+    for i := 0; i < b.N; i++ {
+      if _, ok := linearArgIndex(r, "v", 1); !ok { b.Fatal("unexpected result") }
+    }
+  })
+}
+
+func BenchmarkGetArgValues(b *testing.B) {
+  param := benchLookupParam(b)
+  r := param.getResult()
+  b.Run("indexed", func(b *testing.B) {
+    b.ReportAllocs()
+    for i := 0; i < b.N; i++ {
+      if _, ok := param.GetArgValues("D", -1); !ok { b.Fatal("unexpected result") }
+    }
+  })
+  b.Run("linear", func(b *testing.B) {
+    b.ReportAllocs()
+    for i := 0; i < b.N; i++ {
+      if _, ok := linearArgValues(r, "D", -1); !ok { b.Fatal("unexpected result") }
+    }
+  })
+}
+
+// Number of command line arguments evaluated by the parse benchmark.
+const benchParseArgs = 100000
+
+// Maximum number of allocations per command line argument accepted by Parse.
+const maxParseAllocsPerArg = 1.0
+
+// Used internally. Returns a parameter and a mix of options, attached option arguments and extra arguments with
+// the given number of command line arguments.
+func benchParseParam(n int) (*Parameter, []string) {
+  param := Create()
+  param.AddParameter("define", []string{"D"}, 1)
+  param.AddParameter("level", nil, 1)
+  param.SetParameterTypes("level", TypeInt)
+  for i := 0; i < 100; i++ {
+    param.AddParameter("opt" + strconv.Itoa(i), nil, 0)
+  }
+  args := []string{"app"}
+  for i := 0; len(args) < n / 2; i++ {
+    switch i % 4 {
+      case 0:   args = append(args, "-D", "key" + strconv.Itoa(i))
+      case 1:   args = append(args, "--define=key" + strconv.Itoa(i) + "=value")
+      case 2:   args = append(args, "--opt" + strconv.Itoa(i % 100))
+      default:  args = append(args, "--level", strconv.Itoa(i))
+    }
+  }
+  for i := 0; len(args) < n; i++ {
+    args = append(args, "file" + strconv.Itoa(i))
+  }
+  return param, args
+}
+
+// Parse must not exceed the number of allocations per command line argument given by maxParseAllocsPerArg.
+func TestParseAllocs(t *testing.T) {
+  param, args := benchParseParam(benchParseArgs)
+  allocs := testing.AllocsPerRun(5, func() {
+    if _, err := param.Parse(args); err != nil { t.Fatalf("Parse: %v", err) }
+  })
+  if perArg := allocs / float64(len(args)); perArg > maxParseAllocsPerArg {
+    t.Errorf("Parse: %.2f allocs/arg; want at most %.2f", perArg, maxParseAllocsPerArg)
+  }
+}
+
+func BenchmarkParse(b *testing.B) {
+  param, args := benchParseParam(benchParseArgs)
+  b.ReportAllocs()
+  b.ResetTimer()
+  for i := 0; i < b.N; i++ {
+    if _, err := param.Parse(args); err != nil { b.Fatalf("Parse: %v", err) }
+  }
+  b.StopTimer()
+  b.ReportMetric(testing.AllocsPerRun(1, func() { param.Parse(args) }) / float64(len(args)), "allocs/arg")
+}
+
+// Errors must identify option arguments by index, even if several option arguments share the same placeholder name.
+func TestEvalErrorIndex(t *testing.T) {
+  param := Create()
+  param.AddParameter("num", nil, 2)
+  param.SetParameterTypes("num", TypeInt, TypeFloat)
+  args := []string{"app", "--num", "1", "x"}
+  err := param.Evaluate(args)
+  var e *EvalError
+  if !errors.As(err, &e) || e.Kind != InvalidValue || e.Index != 1 || e.Position != 3 {
+    t.Fatalf("Evaluate = %#v; want InvalidValue at index 1", err)
+  }
+  if s := param.FormatError(err, args); !strings.Contains(s, "hint: expected float") {
+    t.Errorf("FormatError = %q; want float hint", s)
+  }
+
+  err = param.Evaluate([]string{"app", "--num", "1"})
+  if !errors.As(err, &e) || e.Kind != MissingValue || e.Index != 1 {
+    t.Errorf("Evaluate = %#v; want MissingValue at index 1", err)
+  }
+}
+
+// Aliases reassigned by AddParameter must be reported as conflicts by Validate.
+func TestValidateReassignedAlias(t *testing.T) {
+  param := Create()
+  param.AddParameter("alpha", []string{"x"}, 0)
+  param.AddParameter("beta", []string{"x"}, 0)
+  err := param.Validate()
+  var e *DefinitionError
+  if !errors.As(err, &e) || e.Name != "alpha" || e.Alias != "x" || !errors.Is(err, ErrNameConflict) {
+    t.Fatalf("Validate = %v; want name conflict of alpha and x", err)
+  }
+  if d, _ := param.Lookup("x"); d.Name() != "beta" {
+    t.Errorf("Lookup(\"x\") = %s; want beta", d.Name())
+  }
+
+  // resolving the conflict
+  param.RemoveParameterAlias("x")
+  if err := param.Validate(); err != nil {
+    t.Errorf("Validate after alias removal = %v", err)
+  }
+  param.AddParameter("beta", []string{"x"}, 0)
+  param.AddParameter("alpha", []string{"x"}, 0)
+  var list DefinitionErrorList
+  if err := param.Validate(); !errors.As(err, &e) || errors.As(err, &list) || e.Name != "beta" {
+    t.Errorf("Validate after reassignment = %v; want single conflict of beta", err)
+  }
+}
+
+// Typed option handles must not resolve their name by the alias map, which may refer to other parameters.
+func TestOptionNameTakenOver(t *testing.T) {
+  param := Create()
+  foo := Int(param, "foo", nil, 7)
+  param.AddParameter("bar", []string{"foo"}, 1)
+  if err := param.Evaluate([]string{"app", "--bar", "42"}); err != nil {
+    t.Fatalf("Evaluate: %v", err)
+  }
+  if foo.Exists() || foo.Value() != 7 || len(foo.Values()) != 0 {
+    t.Errorf("foo = %v, %d, %v; want false, 7, []", foo.Exists(), foo.Value(), foo.Values())
+  }
+}
+
+func TestParameterEnv(t *testing.T) {
+  param := Create()
+  param.AddParameter("quiet", []string{"q"}, 0)
+  param.SetParameterEnv("quiet", "CMDARGS_TEST_QUIET")
+  param.AddParameter("size", nil, 2)
+  param.SetParameterTypes("size", TypeInt, TypeInt)
+  param.SetParameterEnv("size", "CMDARGS_TEST_SIZE")
+
+  // boolean values
+  for _, c := range []struct{ value string; want bool }{{"true", true}, {"1", true}, {"false", false}, {"0", false}} {
+    t.Setenv("CMDARGS_TEST_QUIET", c.value)
+    if err := param.Evaluate([]string{"app"}); err != nil {
+      t.Fatalf("Evaluate with %q: %v", c.value, err)
+    }
+    if param.GetArgExists("quiet") != c.want {
+      t.Errorf("GetArgExists(\"quiet\") with %q = %v; want %v", c.value, !c.want, c.want)
+    }
+  }
+  t.Setenv("CMDARGS_TEST_QUIET", "maybe")
+  err := param.Evaluate([]string{"app"})
+  var e *EvalError
+  if !errors.As(err, &e) || e.Kind != InvalidValue || e.Option != "quiet" || e.Position != -1 {
+    t.Errorf("Evaluate = %v; want invalid value of quiet", err)
+  }
+  if err := param.Evaluate([]string{"app", "-q"}); err != nil {
+    t.Errorf("Evaluate: %v; environment variable must be ignored if the option is specified", err)
+  }
+  os.Unsetenv("CMDARGS_TEST_QUIET")
+
+  // multiple values
+  t.Setenv("CMDARGS_TEST_SIZE", " 640\t480  32 ")
+  if err := param.Evaluate([]string{"app"}); err != nil {
+    t.Fatalf("Evaluate: %v", err)
+  }
+  arg, ok := param.GetLastArgOf("size")
+  if !ok || len(arg.Arguments) != 2 || arg.Arguments[0].ToInt() != 640 || arg.Arguments[1].ToInt() != 480 {
+    t.Errorf("GetLastArgOf(\"size\") = %v, %v; want 640 480", arg.Arguments, ok)
+  }
+  if err := param.Evaluate([]string{"app", "--size", "1", "2"}); err != nil || param.GetArgCount("size") != 1 {
+    t.Errorf("Evaluate = %v, %d instances; want command line value only", err, param.GetArgCount("size"))
+  }
+  t.Setenv("CMDARGS_TEST_SIZE", "640")
+  if err := param.Evaluate([]string{"app"}); !errors.As(err, &e) || e.Kind != MissingValue || e.Index != 1 {
+    t.Errorf("Evaluate = %v; want missing value at index 1", err)
+  }
+  t.Setenv("CMDARGS_TEST_SIZE", "640 x")
+  if err := param.Evaluate([]string{"app"}); !errors.As(err, &e) || e.Kind != InvalidValue || e.Index != 1 ||
+     e.Value != "x" {
+    t.Errorf("Evaluate = %v; want invalid value \"x\" at index 1", err)
+  }
+}
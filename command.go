@@ -0,0 +1,56 @@
+package cmdargs
+
+// Definition for a subcommand
+type commandType struct {
+  name      string      // Name of the subcommand as specified at the command line
+  param     *Parameter  // Parameter definitions of the subcommand
+}
+
+// AddCommand adds or replaces the subcommand specified by "name".
+//
+// If the first argument following the options of the command line matches a subcommand name, Evaluate passes the
+// remaining arguments, starting with the subcommand name, to the Evaluate function of cmd. The description of the
+// subcommand is defined by cmd.SetProgramDescription.
+func (param *Parameter) AddCommand(name string, cmd *Parameter) {
+  if len(name) == 0 || cmd == nil { return }
+  cmd.parent = param
+  cmd.cmdName = name
+  for _, c := range param.commands {
+    if c.name == name {
+      c.param = cmd
+      return
+    }
+  }
+  param.commands = append(param.commands, &commandType{name: name, param: cmd})
+}
+
+// RemoveCommand removes the subcommand of given name. Returns whether there was a subcommand that could be removed.
+func (param *Parameter) RemoveCommand(name string) bool {
+  for i, c := range param.commands {
+    if c.name == name {
+      param.commands = append(param.commands[:i], param.commands[i+1:]...)
+      return true
+    }
+  }
+  return false
+}
+
+// GetCommand returns the name and parameter definitions of the subcommand evaluated by a previous call to
+// Evaluate. Returns an empty name and nil if no subcommand was specified.
+func (param *Parameter) GetCommand() (name string, cmd *Parameter) {
+  if r := param.getResult(); r.command != nil {
+    name, cmd = r.command.name, r.command.param
+  }
+  return
+}
+
+
+// Used internally. Returns the subcommand of given name, or nil if not available.
+func (param *Parameter) getCommand(name string) *commandType {
+  for _, c := range param.commands {
+    if c.name == name {
+      return c
+    }
+  }
+  return nil
+}
@@ -0,0 +1,88 @@
+package cmdargs
+
+import (
+  "errors"
+  "strings"
+  "testing"
+)
+
+// Help output of subcommands must show the application name evaluated by the same call.
+func TestCommandHelpProgramName(t *testing.T) {
+  param := Create()
+  sub := Create()
+  sub.AddHelpParameter("help", []string{"h"})
+  param.AddCommand("sub", sub)
+
+  var sb strings.Builder
+  sub.SetOutput(&sb)
+  if err := param.Evaluate([]string{"/usr/bin/first"}); err != nil {
+    t.Fatalf("Evaluate: %v", err)
+  }
+  for _, args := range [][]string{{"/usr/bin/app", "sub", "--help"}, {"/usr/bin/app", "sub", "--help", "--bogus"}} {
+    sb.Reset()
+    if _, err := param.Parse(args); !errors.Is(err, ErrHelp) {
+      t.Fatalf("Parse(%q) = %v; want ErrHelp", args, err)
+    }
+    if s := sb.String(); !strings.HasPrefix(s, "Usage: app sub") {
+      t.Errorf("Parse(%q) printed %q; want \"Usage: app sub\" prefix", args, s)
+    }
+  }
+}
+
+// Used internally. Returns parameter definitions with a subcommand.
+func newCommandParam() (param, build *Parameter) {
+  param = Create()
+  param.AddParameter("verbose", []string{"v"}, 0)
+  param.AddParameter("jobs", []string{"j"}, 1)
+  build = Create()
+  build.AddParameter("force", []string{"f"}, 0)
+  build.AddParameter("target", nil, 1)
+  build.AddPositional("dir", 0, 1)
+  param.AddCommand("build", build)
+  return
+}
+
+func TestCommandDispatch(t *testing.T) {
+  param, build := newCommandParam()
+  args := []string{"app", "-v", "-j", "2", "build", "--target", "all", "-f", "src"}
+  if err := param.Evaluate(args); err != nil {
+    t.Fatalf("Evaluate: %v", err)
+  }
+  if name, cmd := param.GetCommand(); name != "build" || cmd != build {
+    t.Fatalf("GetCommand = %q, %p; want build, %p", name, cmd, build)
+  }
+  if !param.GetArgExists("verbose") || param.GetArgParamDefault("jobs", 0, String("")).ToString() != "2" {
+    t.Error("options of the parent command are missing")
+  }
+  if param.GetArgExtraLength() != 0 || param.GetArgExists("force") {
+    t.Error("arguments of the subcommand are evaluated by the parent command")
+  }
+  if build.GetArgSelf() != "build" || !build.GetArgExists("f") ||
+     build.GetArgParamDefault("target", 0, String("")).ToString() != "all" {
+    t.Error("options of the subcommand are missing")
+  }
+  if dir := build.GetPositional("dir"); len(dir) != 1 || dir[0].ToString() != "src" {
+    t.Errorf("GetPositional(\"dir\") = %v; want [src]", dir)
+  }
+  if _, sub := param.getResult().GetCommand(); sub != build.getResult() {
+    t.Error("Result of the subcommand is not stored")
+  }
+
+  // subcommands only evaluate their own options; positions refer to the whole list
+  err := param.Evaluate([]string{"app", "build", "-v"})
+  var e *EvalError
+  if !errors.As(err, &e) || e.Kind != UnrecognizedOption || e.Position != 2 {
+    t.Errorf("Evaluate = %v; want unrecognized option at position 2", err)
+  }
+
+  // unknown commands are extra arguments
+  if err := param.Evaluate([]string{"app", "-v", "bulid", "x"}); err != nil {
+    t.Fatalf("Evaluate: %v", err)
+  }
+  if name, _ := param.GetCommand(); name != "" || param.GetArgExtraLength() != 2 {
+    t.Errorf("GetCommand = %q, %d extra arguments; want none, 2", name, param.GetArgExtraLength())
+  }
+  if build.GetArgExists("force") {
+    t.Error("subcommand retains arguments of a previous call")
+  }
+}
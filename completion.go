@@ -0,0 +1,710 @@
+package cmdargs
+
+import (
+  "fmt"
+  "io"
+  "strings"
+)
+
+// CompletionHint specifies how shell completion scripts complete option arguments.
+type CompletionHint int
+
+const (
+  // CompleteDefault lets the completion scripts decide based on choices, declared types and validators.
+  // Arguments without further information are completed as file names.
+  CompleteDefault CompletionHint = iota
+  // CompleteFile completes file and directory names.
+  CompleteFile
+  // CompleteDir completes directory names.
+  CompleteDir
+  // CompleteNone disables completion.
+  CompleteNone
+)
+
+// SetParameterCompletion defines how shell completion scripts complete the arguments of the parameter specified by
+// "name".
+//
+// Returns whether a parameter definition of given name exists.
+func (param *Parameter) SetParameterCompletion(name string, hint CompletionHint) bool {
+  p, ok := param.aliases[getOptionName(name)]
+  if ok {
+    p.hint = hint
+  }
+  return ok
+}
+
+// WriteBashCompletion writes a bash completion script generated from the parameter definitions to w.
+// The script can be sourced directly or installed into the bash-completion directory. If dynamic completion is
+// enabled by EnableCompletion, the script delegates to the application instead.
+func (param *Parameter) WriteBashCompletion(w io.Writer) error {
+  var sb strings.Builder
+  prog := param.getProgramName()
+  if param.dynamic {
+    param.writeBashDelegate(&sb, prog)
+    _, err := io.WriteString(w, sb.String())
+    return err
+  }
+  fn := "_" + getIdentifier(prog) + "_complete"
+
+  sb.WriteString("# bash completion for " + prog + "\n")
+  sb.WriteString(fn + "() {\n")
+  sb.WriteString("  local cur=\"${COMP_WORDS[COMP_CWORD]}\"\n")
+  sb.WriteString("  local cpath=" + shellQuote(prog) + " opt='' need=0 argi=0 pos=0 ended=0 i w n\n")
+  sb.WriteString("  [[ $cur == = ]] && cur=''\n")
+  sb.WriteString("  for ((i = 1; i < COMP_CWORD; i++)); do\n")
+  sb.WriteString("    w=\"${COMP_WORDS[i]}\"\n")
+  sb.WriteString("    [[ $w == = ]] && continue\n")
+  param.writeShellScan(&sb, prog)
+  sb.WriteString("  done\n")
+
+  sb.WriteString("  if ((need > 0)); then\n")
+  sb.WriteString("    case \"$opt:$argi\" in\n")
+  param.forEachValueCompletion(prog, func(key string, choices []string, hint CompletionHint) {
+    writeBashReply(&sb, "      " + shellQuote(key), choices, hint)
+  })
+  sb.WriteString("      *) COMPREPLY=($(compgen -f -- \"$cur\")) ;;\n")
+  sb.WriteString("    esac\n")
+  sb.WriteString("    return 0\n")
+  sb.WriteString("  fi\n")
+  slots := param.getPositionalSlots(prog)
+  if len(slots) > 0 {
+    sb.WriteString("  if ((ended)); then\n")
+    writeBashPositionals(&sb, "    ", slots)
+    sb.WriteString("    return 0\n")
+    sb.WriteString("  fi\n")
+  } else {
+    sb.WriteString("  if ((ended)); then COMPREPLY=($(compgen -f -- \"$cur\")); return 0; fi\n")
+  }
+  sb.WriteString("  case \"$cpath\" in\n")
+  for _, node := range param.getCompletionNodes(prog) {
+    sb.WriteString("    " + shellQuote(node.path) + ")\n")
+    sb.WriteString("      if [[ $cur == -* ]]; then\n")
+    sb.WriteString("        COMPREPLY=($(compgen -W " + shellQuote(strings.Join(node.getOptionNames(), " ")) +
+                   " -- \"$cur\"))\n")
+    sb.WriteString("      else\n")
+    if len(node.param.commands) > 0 {
+      sb.WriteString("        COMPREPLY=($(compgen -W " + shellQuote(strings.Join(node.getCommandNames(), " ")) +
+                     " -- \"$cur\"))\n")
+    } else if len(slots) > 0 {
+      writeBashPositionals(&sb, "        ", slots)
+    } else {
+      sb.WriteString("        COMPREPLY=($(compgen -f -- \"$cur\"))\n")
+    }
+    sb.WriteString("      fi ;;\n")
+  }
+  sb.WriteString("  esac\n")
+  sb.WriteString("  return 0\n")
+  sb.WriteString("}\n")
+  sb.WriteString("complete -F " + fn + " " + shellQuote(prog) + "\n")
+
+  _, err := io.WriteString(w, sb.String())
+  return err
+}
+
+// WriteZshCompletion writes a zsh completion script generated from the parameter definitions to w.
+// The script can be sourced directly or installed as "_<application name>" into a directory listed in fpath.
+// If dynamic completion is enabled by EnableCompletion, the script delegates to the application instead.
+func (param *Parameter) WriteZshCompletion(w io.Writer) error {
+  var sb strings.Builder
+  prog := param.getProgramName()
+  if param.dynamic {
+    param.writeZshDelegate(&sb, prog)
+    _, err := io.WriteString(w, sb.String())
+    return err
+  }
+  fn := "_" + getIdentifier(prog)
+
+  sb.WriteString("#compdef " + prog + "\n\n")
+  sb.WriteString(fn + "() {\n")
+  sb.WriteString("  local cur=\"${words[CURRENT]}\"\n")
+  sb.WriteString("  local cpath=" + shellQuote(prog) + " opt='' need=0 argi=0 pos=0 ended=0 i w n\n")
+  sb.WriteString("  local -a items\n")
+  sb.WriteString("  for ((i = 2; i < CURRENT; i++)); do\n")
+  sb.WriteString("    w=\"${words[i]}\"\n")
+  param.writeShellScan(&sb, prog)
+  sb.WriteString("  done\n")
+
+  sb.WriteString("  if ((need > 0)); then\n")
+  sb.WriteString("    case \"$opt:$argi\" in\n")
+  param.forEachValueCompletion(prog, func(key string, choices []string, hint CompletionHint) {
+    writeZshReply(&sb, "      " + shellQuote(key), choices, hint)
+  })
+  sb.WriteString("      *) _files ;;\n")
+  sb.WriteString("    esac\n")
+  sb.WriteString("    return\n")
+  sb.WriteString("  fi\n")
+  slots := param.getPositionalSlots(prog)
+  if len(slots) > 0 {
+    sb.WriteString("  if ((ended)); then\n")
+    writeZshPositionals(&sb, "    ", slots)
+    sb.WriteString("    return\n")
+    sb.WriteString("  fi\n")
+  } else {
+    sb.WriteString("  if ((ended)); then _files; return; fi\n")
+  }
+  sb.WriteString("  case \"$cpath\" in\n")
+  for _, node := range param.getCompletionNodes(prog) {
+    sb.WriteString("    " + shellQuote(node.path) + ")\n")
+    sb.WriteString("      if [[ $cur == -* ]]; then\n")
+    sb.WriteString("        items=(" + strings.Join(node.getDescribeItems(true), " ") + ")\n")
+    sb.WriteString("        _describe 'option' items\n")
+    sb.WriteString("      else\n")
+    if len(node.param.commands) > 0 {
+      sb.WriteString("        items=(" + strings.Join(node.getDescribeItems(false), " ") + ")\n")
+      sb.WriteString("        _describe 'command' items\n")
+    } else if len(slots) > 0 {
+      writeZshPositionals(&sb, "        ", slots)
+    } else {
+      sb.WriteString("        _files\n")
+    }
+    sb.WriteString("      fi ;;\n")
+  }
+  sb.WriteString("  esac\n")
+  sb.WriteString("}\n\n")
+  sb.WriteString("if [ \"$funcstack[1]\" = " + shellQuote(fn) + " ]; then\n")
+  sb.WriteString("  " + fn + " \"$@\"\n")
+  sb.WriteString("else\n")
+  sb.WriteString("  compdef " + fn + " " + shellQuote(prog) + "\n")
+  sb.WriteString("fi\n")
+
+  _, err := io.WriteString(w, sb.String())
+  return err
+}
+
+// WriteFishCompletion writes a fish completion script generated from the parameter definitions to w.
+// The script can be sourced directly or installed as "<application name>.fish" into the fish completions directory.
+// If dynamic completion is enabled by EnableCompletion, the script delegates to the application instead.
+func (param *Parameter) WriteFishCompletion(w io.Writer) error {
+  var sb strings.Builder
+  prog := param.getProgramName()
+  if param.dynamic {
+    param.writeFishDelegate(&sb, prog)
+    _, err := io.WriteString(w, sb.String())
+    return err
+  }
+  fn := "__" + getIdentifier(prog) + "_complete"
+
+  sb.WriteString("# fish completion for " + prog + "\n")
+  sb.WriteString("function " + fn + "\n")
+  sb.WriteString("    set -l words (commandline -opc)\n")
+  sb.WriteString("    set -l cur (commandline -ct)\n")
+  sb.WriteString("    set -l cpath " + fishQuote(prog) + "\n")
+  sb.WriteString("    set -l opt ''\n")
+  sb.WriteString("    set -l need 0\n")
+  sb.WriteString("    set -l argi 0\n")
+  sb.WriteString("    set -l pos 0\n")
+  sb.WriteString("    set -l ended 0\n")
+  sb.WriteString("    set -e words[1]\n")
+  sb.WriteString("    for w in $words\n")
+  sb.WriteString("        if test $need -gt 0\n")
+  sb.WriteString("            set need (math $need - 1)\n")
+  sb.WriteString("            set argi (math $argi + 1)\n")
+  sb.WriteString("            continue\n")
+  sb.WriteString("        end\n")
+  sb.WriteString("        if test $ended -eq 1\n")
+  sb.WriteString("            set pos (math $pos + 1)\n")
+  sb.WriteString("            continue\n")
+  sb.WriteString("        end\n")
+  sb.WriteString("        if string match -q -- '-?*' $w\n")
+  sb.WriteString("            set -l n (string replace -r -- '=.*$' '' $w)\n")
+  sb.WriteString("            set n (string replace -r -- '^--?' '' $n)\n")
+  sb.WriteString("            set opt \"$cpath:$n\"\n")
+  sb.WriteString("            set need 0\n")
+  sb.WriteString("            set argi 0\n")
+  sb.WriteString("            switch $opt\n")
+  for _, node := range param.getCompletionNodes(prog) {
+    for _, def := range node.param.defs {
+      if def.numArgs == 0 { continue }
+      sb.WriteString("                case " + strings.Join(node.getOptionKeys(def, fishQuote), " ") + "\n")
+      sb.WriteString("                    set opt " + fishQuote(node.path + ":" + def.name) + "\n")
+      sb.WriteString(fmt.Sprintf("                    set need %d\n", def.numArgs))
+    }
+  }
+  sb.WriteString("            end\n")
+  sb.WriteString("            if string match -q -- '*=*' $w; and test $need -gt 0\n")
+  sb.WriteString("                set need (math $need - 1)\n")
+  sb.WriteString("                set argi 1\n")
+  sb.WriteString("            end\n")
+  sb.WriteString("        else\n")
+  sb.WriteString("            switch \"$cpath:$w\"\n")
+  for _, node := range param.getCompletionNodes(prog) {
+    for _, c := range node.param.commands {
+      sb.WriteString("                case " + fishQuote(node.path + ":" + c.name) + "\n")
+      sb.WriteString("                    set cpath " + fishQuote(node.path + " " + c.name) + "\n")
+    }
+  }
+  sb.WriteString("                case '*'\n")
+  sb.WriteString("                    set ended 1\n")
+  sb.WriteString("                    set pos 1\n")
+  sb.WriteString("            end\n")
+  sb.WriteString("        end\n")
+  sb.WriteString("    end\n")
+
+  sb.WriteString("    if test $need -gt 0\n")
+  sb.WriteString("        switch \"$opt:$argi\"\n")
+  param.forEachValueCompletion(prog, func(key string, choices []string, hint CompletionHint) {
+    writeFishReply(&sb, "            ", fishQuote(key), choices, hint)
+  })
+  sb.WriteString("            case '*'\n")
+  sb.WriteString("                __fish_complete_path $cur\n")
+  sb.WriteString("        end\n")
+  sb.WriteString("        return\n")
+  sb.WriteString("    end\n")
+  slots := param.getPositionalSlots(prog)
+  sb.WriteString("    if test $ended -eq 1\n")
+  if len(slots) > 0 {
+    writeFishPositionals(&sb, "        ", slots)
+  } else {
+    sb.WriteString("        __fish_complete_path $cur\n")
+  }
+  sb.WriteString("        return\n")
+  sb.WriteString("    end\n")
+  sb.WriteString("    switch $cpath\n")
+  for _, node := range param.getCompletionNodes(prog) {
+    sb.WriteString("        case " + fishQuote(node.path) + "\n")
+    sb.WriteString("            if string match -q -- '-*' $cur\n")
+    for _, def := range node.param.defs {
+      desc := fishQuote(getSummary(def.desc))
+      for _, name := range def.getNames() {
+        sb.WriteString("                printf '%s\\t%s\\n' " + fishQuote(name) + " " + desc + "\n")
+      }
+    }
+    sb.WriteString("            else\n")
+    if len(node.param.commands) > 0 {
+      for _, c := range node.param.commands {
+        sb.WriteString("                printf '%s\\t%s\\n' " + fishQuote(c.name) + " " +
+                       fishQuote(getSummary(c.param.progDesc)) + "\n")
+      }
+    } else if len(slots) > 0 {
+      writeFishPositionals(&sb, "                ", slots)
+    } else {
+      sb.WriteString("                __fish_complete_path $cur\n")
+    }
+    sb.WriteString("            end\n")
+  }
+  sb.WriteString("    end\n")
+  sb.WriteString("end\n")
+  sb.WriteString("complete -c " + fishQuote(prog) + " -f -a '(" + fn + ")'\n")
+
+  _, err := io.WriteString(w, sb.String())
+  return err
+}
+
+// WritePowerShellCompletion writes a PowerShell completion script generated from the parameter definitions to w.
+// The script registers a native argument completer and can be dot-sourced, e.g. from the PowerShell profile.
+// File and directory names are completed by PowerShell itself. If dynamic completion is enabled by
+// EnableCompletion, the script delegates to the application instead.
+func (param *Parameter) WritePowerShellCompletion(w io.Writer) error {
+  var sb strings.Builder
+  prog := param.getProgramName()
+  sb.WriteString("# PowerShell completion for " + prog + "\n")
+  sb.WriteString("Register-ArgumentCompleter -Native -CommandName " + psQuote(prog) + " -ScriptBlock {\n")
+  sb.WriteString("    param($wordToComplete, $commandAst, $cursorPosition)\n")
+  if param.dynamic {
+    param.writePowerShellDelegate(&sb)
+    sb.WriteString("}\n")
+    _, err := io.WriteString(w, sb.String())
+    return err
+  }
+
+  for _, table := range []string{"opts", "cmds", "choices", "items", "subs"} {
+    sb.WriteString("    $" + table + " = [Collections.Generic.Dictionary[string, object]]::new(" +
+                   "[StringComparer]::Ordinal)\n")
+  }
+  for _, node := range param.getCompletionNodes(prog) {
+    for _, def := range node.param.defs {
+      if def.numArgs == 0 { continue }
+      for _, key := range node.getOptionKeys(def, psQuote) {
+        sb.WriteString("    $opts[" + key + "] = @(" + psQuote(node.path + ":" + def.name) +
+                       fmt.Sprintf(", %d)\n", def.numArgs))
+      }
+    }
+    for _, c := range node.param.commands {
+      sb.WriteString("    $cmds[" + psQuote(node.path + ":" + c.name) + "] = " +
+                     psQuote(node.path + " " + c.name) + "\n")
+    }
+    items := make([]string, 0)
+    for _, def := range node.param.defs {
+      for _, name := range def.getNames() {
+        items = append(items, psQuote(name), psQuote(getTooltip(name, def.desc)))
+      }
+    }
+    sb.WriteString("    $items[" + psQuote(node.path) + "] = @(" + strings.Join(items, ", ") + ")\n")
+    items = items[:0]
+    for _, c := range node.param.commands {
+      items = append(items, psQuote(c.name), psQuote(getTooltip(c.name, c.param.progDesc)))
+    }
+    sb.WriteString("    $subs[" + psQuote(node.path) + "] = @(" + strings.Join(items, ", ") + ")\n")
+  }
+  param.forEachValueCompletion(prog, func(key string, choices []string, hint CompletionHint) {
+    if len(choices) == 0 { return }
+    quoted := make([]string, len(choices))
+    for i, c := range choices { quoted[i] = psQuote(c) }
+    sb.WriteString("    $choices[" + psQuote(key) + "] = @(" + strings.Join(quoted, ", ") + ")\n")
+  })
+  for _, slot := range param.getPositionalSlots(prog) {
+    quoted := make([]string, len(slot.choices))
+    for i, c := range slot.choices { quoted[i] = psQuote(c) }
+    sb.WriteString("    $choices[" + psQuote(slot.key) + "] = @(" + strings.Join(quoted, ", ") + ")\n")
+  }
+
+  sb.WriteString("    $words = @($commandAst.CommandElements |\n")
+  sb.WriteString("               Where-Object { $_.Extent.EndOffset -lt $cursorPosition } |\n")
+  sb.WriteString("               ForEach-Object { $_.Extent.Text })\n")
+  sb.WriteString("    $cpath = " + psQuote(prog) + "; $opt = ''; $need = 0; $argi = 0; $pos = 0; $ended = $false\n")
+  sb.WriteString("    foreach ($w in ($words | Select-Object -Skip 1)) {\n")
+  sb.WriteString("        if ($need -gt 0) { $need--; $argi++; continue }\n")
+  sb.WriteString("        if ($ended) { $pos++; continue }\n")
+  sb.WriteString("        if ($w -like '-?*') {\n")
+  sb.WriteString("            $opt = $cpath + ':' + ((($w -split '=', 2)[0]) -replace '^--?', '')\n")
+  sb.WriteString("            $need = 0; $argi = 0\n")
+  sb.WriteString("            if ($opts.ContainsKey($opt)) { $opt, $need = $opts[$opt] }\n")
+  sb.WriteString("            if ($w.Contains('=') -and $need -gt 0) { $need--; $argi = 1 }\n")
+  sb.WriteString("        } elseif ($cmds.ContainsKey($cpath + ':' + $w)) {\n")
+  sb.WriteString("            $cpath = $cmds[$cpath + ':' + $w]\n")
+  sb.WriteString("        } else {\n")
+  sb.WriteString("            $ended = $true; $pos = 1\n")
+  sb.WriteString("        }\n")
+  sb.WriteString("    }\n")
+  sb.WriteString("    $pre = ''; $cur = $wordToComplete\n")
+  sb.WriteString("    if ($need -eq 0 -and -not $ended -and $cur -match '^(--?[^=]+=)(.*)$') {\n")
+  sb.WriteString("        $pre = $Matches[1]; $cur = $Matches[2]\n")
+  sb.WriteString("        $opt = $cpath + ':' + ($pre.TrimEnd('=') -replace '^--?', ''); $argi = 0\n")
+  sb.WriteString("        if ($opts.ContainsKey($opt)) { $opt, $need = $opts[$opt] }\n")
+  sb.WriteString("    }\n")
+  sb.WriteString("    $list = @()\n")
+  sb.WriteString("    if ($need -gt 0) {\n")
+  sb.WriteString("        $key = $opt + ':' + $argi\n")
+  sb.WriteString("        if ($choices.ContainsKey($key)) { $choices[$key] | ForEach-Object { $list += $_, $_ } }\n")
+  sb.WriteString("    } elseif (-not $ended -and $cur -like '-*') {\n")
+  sb.WriteString("        $list = $items[$cpath]\n")
+  sb.WriteString("    } elseif (-not $ended -and $subs[$cpath].Count -gt 0) {\n")
+  sb.WriteString("        $list = $subs[$cpath]\n")
+  sb.WriteString("    } else {\n")
+  sb.WriteString("        $key = $cpath + ':#' + $pos\n")
+  sb.WriteString("        if (-not $choices.ContainsKey($key)) { $key = $cpath + ':#' }\n")
+  sb.WriteString("        if ($choices.ContainsKey($key)) { $choices[$key] | ForEach-Object { $list += $_, $_ } }\n")
+  sb.WriteString("    }\n")
+  sb.WriteString("    for ($i = 0; $i -lt $list.Count; $i += 2) {\n")
+  sb.WriteString("        if ($list[$i].StartsWith($cur, [StringComparison]::Ordinal)) {\n")
+  sb.WriteString("            [Management.Automation.CompletionResult]::new($pre + $list[$i], $list[$i],\n")
+  sb.WriteString("                'ParameterValue', $list[$i + 1])\n")
+  sb.WriteString("        }\n")
+  sb.WriteString("    }\n")
+  sb.WriteString("}\n")
+
+  _, err := io.WriteString(w, sb.String())
+  return err
+}
+
+
+// Used internally. Writes the loop body shared by the bash and zsh completion scripts, which scans the word in
+// variable "w". It keeps track of the current subcommand path in "cpath", the option in "opt", the number of
+// missing option arguments in "need", the index of the current option argument in "argi", and whether the options
+// have ended in "ended". "pos" counts the extra arguments.
+func (param *Parameter) writeShellScan(sb *strings.Builder, prog string) {
+  sb.WriteString("    if ((need > 0)); then need=$((need - 1)); argi=$((argi + 1)); continue; fi\n")
+  sb.WriteString("    if ((ended)); then pos=$((pos + 1)); continue; fi\n")
+  sb.WriteString("    if [[ $w == -?* ]]; then\n")
+  sb.WriteString("      n=\"${w%%=*}\"; n=\"${n#-}\"; n=\"${n#-}\"\n")
+  sb.WriteString("      opt=\"$cpath:$n\" need=0 argi=0\n")
+  sb.WriteString("      case \"$opt\" in\n")
+  for _, node := range param.getCompletionNodes(prog) {
+    for _, def := range node.param.defs {
+      if def.numArgs == 0 { continue }
+      sb.WriteString("        " + strings.Join(node.getOptionKeys(def, shellQuote), "|") + ") opt=" +
+                     shellQuote(node.path + ":" + def.name) + fmt.Sprintf(" need=%d ;;\n", def.numArgs))
+    }
+  }
+  sb.WriteString("      esac\n")
+  sb.WriteString("      [[ $w == *=* ]] && ((need > 0)) && need=$((need - 1)) && argi=1\n")
+  sb.WriteString("    else\n")
+  sb.WriteString("      case \"$cpath:$w\" in\n")
+  for _, node := range param.getCompletionNodes(prog) {
+    for _, c := range node.param.commands {
+      sb.WriteString("        " + shellQuote(node.path + ":" + c.name) + ") cpath=" +
+                     shellQuote(node.path + " " + c.name) + " ;;\n")
+    }
+  }
+  sb.WriteString("        *) ended=1 pos=1 ;;\n")
+  sb.WriteString("      esac\n")
+  sb.WriteString("    fi\n")
+}
+
+// Used internally. Associates a command path with its parameter definitions for completion scripts.
+type complNode struct {
+  path    string      // Application name, followed by subcommand names, separated by spaces
+  param   *Parameter
+}
+
+// Used internally. Returns the command and all subcommands, recursively.
+func (param *Parameter) getCompletionNodes(path string) []complNode {
+  retVal := []complNode{{path: path, param: param}}
+  for _, c := range param.commands {
+    retVal = append(retVal, c.param.getCompletionNodes(path + " " + c.name)...)
+  }
+  return retVal
+}
+
+// Used internally. Invokes fn for every option argument of the command and its subcommands with specific completion
+// requirements. key is of the form "<path>:<option name>:<argument index>".
+func (param *Parameter) forEachValueCompletion(prog string,
+                                               fn func(key string, choices []string, hint CompletionHint)) {
+  for _, node := range param.getCompletionNodes(prog) {
+    for _, def := range node.param.defs {
+      for i := 0; i < def.numArgs; i++ {
+        choices, hint := def.getCompletion(i)
+        if len(choices) > 0 || hint != CompleteDefault && hint != CompleteFile {
+          fn(fmt.Sprintf("%s:%s:%d", node.path, def.name, i), choices, hint)
+        }
+      }
+    }
+  }
+}
+
+// Used internally. Returns the case labels for all names of the given option, quoted by the given function.
+func (node complNode) getOptionKeys(def *paramType, quote func(string) string) []string {
+  retVal := make([]string, 0, len(def.aliases) + 1)
+  for _, a := range append([]string{def.name}, def.aliases...) {
+    retVal = append(retVal, quote(node.path + ":" + a))
+  }
+  return retVal
+}
+
+// Used internally. Completion requirements of the extra arguments assigned to a positional argument.
+type complSlot struct {
+  key       string          // "<path>:#<index>", or "<path>:#" for all remaining extra arguments
+  rest      bool            // Indicates whether the slot applies to all remaining extra arguments
+  choices   []string
+  hint      CompletionHint
+}
+
+// Used internally. Returns the completion requirements of the positional arguments of the command and its
+// subcommands. Extra arguments without specific requirements are omitted, unless they precede a slot that applies
+// to all remaining extra arguments.
+func (param *Parameter) getPositionalSlots(prog string) []complSlot {
+  retVal := make([]complSlot, 0)
+  for _, node := range param.getCompletionNodes(prog) {
+    all := false
+    for _, p := range node.param.positionals {
+      if p.maxArgs < 0 {
+        all = isSpecificCompletion(p.getCompletion())
+        break
+      }
+    }
+    index := 0
+    for _, p := range node.param.positionals {
+      choices, hint := p.getCompletion()
+      if p.maxArgs < 0 {
+        if all {
+          retVal = append(retVal, complSlot{key: node.path + ":#", rest: true, choices: choices, hint: hint})
+        }
+        break
+      }
+      for i := 0; i < p.maxArgs; i, index = i+1, index+1 {
+        if all || isSpecificCompletion(choices, hint) {
+          retVal = append(retVal, complSlot{key: fmt.Sprintf("%s:#%d", node.path, index), choices: choices,
+                                            hint: hint})
+        }
+      }
+    }
+  }
+  return retVal
+}
+
+// Used internally. Returns whether the given choices and completion hint differ from the default file completion.
+func isSpecificCompletion(choices []string, hint CompletionHint) bool {
+  return len(choices) > 0 || hint != CompleteDefault && hint != CompleteFile
+}
+
+// Used internally. Writes a bash case item that completes a value.
+func writeBashReply(sb *strings.Builder, label string, choices []string, hint CompletionHint) {
+  sb.WriteString(label + ") ")
+  switch {
+    case len(choices) > 0:     sb.WriteString("COMPREPLY=($(compgen -W " + shellQuote(strings.Join(choices, " ")) +
+                                              " -- \"$cur\"))")
+    case hint == CompleteDir:  sb.WriteString("COMPREPLY=($(compgen -d -- \"$cur\"))")
+    case hint == CompleteNone: sb.WriteString("COMPREPLY=()")
+    default:                   sb.WriteString("COMPREPLY=($(compgen -f -- \"$cur\"))")
+  }
+  sb.WriteString(" ;;\n")
+}
+
+// Used internally. Writes a bash case statement that completes positional arguments.
+func writeBashPositionals(sb *strings.Builder, indent string, slots []complSlot) {
+  sb.WriteString(indent + "case \"$cpath:#$pos\" in\n")
+  for _, slot := range slots {
+    label := shellQuote(slot.key)
+    if slot.rest { label += "*" }
+    writeBashReply(sb, indent + "  " + label, slot.choices, slot.hint)
+  }
+  sb.WriteString(indent + "  *) COMPREPLY=($(compgen -f -- \"$cur\")) ;;\n")
+  sb.WriteString(indent + "esac\n")
+}
+
+// Used internally. Writes a zsh case item that completes a value.
+func writeZshReply(sb *strings.Builder, label string, choices []string, hint CompletionHint) {
+  sb.WriteString(label + ") ")
+  switch {
+    case len(choices) > 0:
+      quoted := make([]string, len(choices))
+      for i, c := range choices { quoted[i] = shellQuote(c) }
+      sb.WriteString("compadd -- " + strings.Join(quoted, " "))
+    case hint == CompleteDir:
+      sb.WriteString("_files -/")
+    case hint == CompleteNone:
+      sb.WriteString("return 1")
+    default:
+      sb.WriteString("_files")
+  }
+  sb.WriteString(" ;;\n")
+}
+
+// Used internally. Writes a zsh case statement that completes positional arguments.
+func writeZshPositionals(sb *strings.Builder, indent string, slots []complSlot) {
+  sb.WriteString(indent + "case \"$cpath:#$pos\" in\n")
+  for _, slot := range slots {
+    label := shellQuote(slot.key)
+    if slot.rest { label += "*" }
+    writeZshReply(sb, indent + "  " + label, slot.choices, slot.hint)
+  }
+  sb.WriteString(indent + "  *) _files ;;\n")
+  sb.WriteString(indent + "esac\n")
+}
+
+// Used internally. Writes a fish case item that completes a value.
+func writeFishReply(sb *strings.Builder, indent, label string, choices []string, hint CompletionHint) {
+  sb.WriteString(indent + "case " + label + "\n")
+  switch {
+    case len(choices) > 0:
+      quoted := make([]string, len(choices))
+      for i, c := range choices { quoted[i] = fishQuote(c) }
+      sb.WriteString(indent + "    printf '%s\\n' " + strings.Join(quoted, " ") + "\n")
+    case hint == CompleteDir:
+      sb.WriteString(indent + "    __fish_complete_directories $cur\n")
+    case hint == CompleteNone:
+    default:
+      sb.WriteString(indent + "    __fish_complete_path $cur\n")
+  }
+}
+
+// Used internally. Writes a fish switch statement that completes positional arguments.
+func writeFishPositionals(sb *strings.Builder, indent string, slots []complSlot) {
+  sb.WriteString(indent + "switch \"$cpath:#$pos\"\n")
+  for _, slot := range slots {
+    label := slot.key
+    if slot.rest { label += "*" }
+    writeFishReply(sb, indent + "    ", fishQuote(label), slot.choices, slot.hint)
+  }
+  sb.WriteString(indent + "    case '*'\n")
+  sb.WriteString(indent + "        __fish_complete_path $cur\n")
+  sb.WriteString(indent + "end\n")
+}
+
+// Used internally. Returns all option names of the node with prefix.
+func (node complNode) getOptionNames() []string {
+  retVal := make([]string, 0, len(node.param.defs))
+  for _, def := range node.param.defs {
+    retVal = append(retVal, def.getNames()...)
+  }
+  return retVal
+}
+
+// Used internally. Returns all subcommand names of the node.
+func (node complNode) getCommandNames() []string {
+  retVal := make([]string, 0, len(node.param.commands))
+  for _, c := range node.param.commands {
+    retVal = append(retVal, c.name)
+  }
+  return retVal
+}
+
+// Used internally. Returns quoted "name:description" items for the zsh _describe function, either for options or
+// for subcommands.
+func (node complNode) getDescribeItems(options bool) []string {
+  retVal := make([]string, 0)
+  if options {
+    for _, def := range node.param.defs {
+      for _, name := range def.getNames() {
+        retVal = append(retVal, shellQuote(name + ":" + getSummary(def.desc)))
+      }
+    }
+  } else {
+    for _, c := range node.param.commands {
+      retVal = append(retVal, shellQuote(strings.ReplaceAll(c.name, ":", "\\:") + ":" + getSummary(c.param.progDesc)))
+    }
+  }
+  return retVal
+}
+
+// Used internally. Returns the allowed choices and the completion hint for the option argument at the specified
+// index.
+func (def *paramType) getCompletion(index int) (choices []string, hint CompletionHint) {
+  vtype := TypeString
+  if index < len(def.types) { vtype = def.types[index] }
+  return getValueCompletion(def.hint, def.getValidators(index), vtype)
+}
+
+// Used internally. Returns the allowed choices and the completion hint for a value with the given explicit hint,
+// validators and declared type.
+func getValueCompletion(explicit CompletionHint, validators []Validator,
+                        vtype ValueType) (choices []string, hint CompletionHint) {
+  choices = getValidatorChoices(validators)
+  hint = explicit
+  if hint != CompleteDefault { return }
+
+  for _, v := range validators {
+    if v, ok := v.(*PathValidator); ok {
+      if v.DirOnly { hint = CompleteDir } else { hint = CompleteFile }
+      return
+    }
+  }
+  switch vtype {
+    case TypeBool:
+      if len(choices) == 0 { choices = []string{"true", "false"} }
+    case TypeInt, TypeUint, TypeFloat:
+      hint = CompleteNone
+  }
+  return
+}
+
+// Used internally. Returns the first line of the given text.
+func getSummary(text string) string {
+  return strings.TrimSpace(strings.SplitN(strings.TrimSpace(text), "\n", 2)[0])
+}
+
+// Used internally. Returns the first line of the given description, or name if the description is empty.
+func getTooltip(name, desc string) string {
+  if summary := getSummary(desc); len(summary) > 0 { return summary }
+  return name
+}
+
+// Used internally. Returns a shell function name derived from the given string.
+func getIdentifier(s string) string {
+  var sb strings.Builder
+  for _, ch := range s {
+    if (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9') || ch == '_' {
+      sb.WriteRune(ch)
+    } else {
+      sb.WriteByte('_')
+    }
+  }
+  return sb.String()
+}
+
+// Used internally. Returns the given string in single quotes for POSIX shells.
+func shellQuote(s string) string {
+  return "'" + strings.ReplaceAll(s, "'", "'\\''") + "'"
+}
+
+// Used internally. Returns the given string in single quotes for the fish shell.
+func fishQuote(s string) string {
+  s = strings.ReplaceAll(s, "\\", "\\\\")
+  s = strings.ReplaceAll(s, "'", "\\'")
+  return "'" + s + "'"
+}
+
+// Used internally. Returns the given string in single quotes for PowerShell.
+func psQuote(s string) string {
+  return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
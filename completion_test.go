@@ -0,0 +1,22 @@
+package cmdargs
+
+import (
+  "strings"
+  "testing"
+)
+
+func TestWriteBashCompletion(t *testing.T) {
+  var sb strings.Builder
+  if err := newDocParam().WriteBashCompletion(&sb); err != nil {
+    t.Fatalf("WriteBashCompletion: %v", err)
+  }
+  checkGolden(t, "tool.bash", sb.String())
+}
+
+func TestWriteZshCompletion(t *testing.T) {
+  var sb strings.Builder
+  if err := newDocParam().WriteZshCompletion(&sb); err != nil {
+    t.Fatalf("WriteZshCompletion: %v", err)
+  }
+  checkGolden(t, "tool.zsh", sb.String())
+}
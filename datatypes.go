@@ -1,6 +1,8 @@
 package cmdargs
 
 import (
+  "fmt"
+  "math"
   "strconv"
 )
 
@@ -124,3 +126,133 @@ func (t String) ToFloat() float64 {
   ret, _ := t.Float()
   return ret
 }
+
+
+// ValueType specifies the expected type of an option argument. See Parameter.SetParameterTypes for details.
+type ValueType int
+
+const (
+  // TypeString accepts any argument. It is the default for option arguments without declared type.
+  TypeString ValueType = iota
+  // TypeInt accepts signed integer numbers in decimal, hexadecimal ("0x" prefix) or octal ("0" prefix) notation.
+  TypeInt
+  // TypeUint accepts unsigned integer numbers in decimal, hexadecimal ("0x" prefix) or octal ("0" prefix) notation.
+  TypeUint
+  // TypeFloat accepts floating point numbers.
+  TypeFloat
+  // TypeBool accepts the boolean strings recognized by String.Bool, except for numeric values.
+  TypeBool
+)
+
+// String returns a human-readable name of the value type.
+func (t ValueType) String() string {
+  switch t {
+    case TypeInt:   return "integer"
+    case TypeUint:  return "unsigned integer"
+    case TypeFloat: return "float"
+    case TypeBool:  return "boolean"
+    default:        return "string"
+  }
+}
+
+// Used internally. Converts the given argument into a Generic of the value type. Returns whether the conversion
+// was successful.
+func (t ValueType) convert(v Generic) (Generic, bool) {
+  s := v.ToString()
+  switch t {
+    case TypeInt:
+      if i, err := strconv.ParseInt(s, 0, 64); err == nil { return intValue{raw: s, v: i}, true }
+    case TypeUint:
+      if u, err := strconv.ParseUint(s, 0, 64); err == nil { return uintValue{raw: s, v: u}, true }
+    case TypeFloat:
+      if f, err := strconv.ParseFloat(s, 64); err == nil { return floatValue{raw: s, v: f}, true }
+    case TypeBool:
+      if b, err := strconv.ParseBool(s); err == nil { return boolValue{raw: s, v: b}, true }
+    default:
+      return v, true
+  }
+  return v, false
+}
+
+
+// Pre-converted signed integer argument.
+type intValue struct {
+  raw string
+  v   int64
+}
+
+func (t intValue) String() (string, bool) { return t.raw, true }
+func (t intValue) ToString() string { return t.raw }
+func (t intValue) Bool() (bool, bool) { return t.v != 0, true }
+func (t intValue) ToBool() bool { return t.v != 0 }
+func (t intValue) Int() (int64, bool) { return t.v, true }
+func (t intValue) ToInt() int64 { return t.v }
+func (t intValue) Uint() (uint64, bool) { return uint64(t.v), t.v >= 0 }
+func (t intValue) ToUint() uint64 { if t.v < 0 { return 0 }; return uint64(t.v) }
+func (t intValue) Float() (float64, bool) { return float64(t.v), true }
+func (t intValue) ToFloat() float64 { return float64(t.v) }
+func (t intValue) Format(f fmt.State, verb rune) { formatValue(f, verb, t.raw, t.v) }
+
+// Pre-converted unsigned integer argument.
+type uintValue struct {
+  raw string
+  v   uint64
+}
+
+func (t uintValue) String() (string, bool) { return t.raw, true }
+func (t uintValue) ToString() string { return t.raw }
+func (t uintValue) Bool() (bool, bool) { return t.v != 0, true }
+func (t uintValue) ToBool() bool { return t.v != 0 }
+func (t uintValue) Int() (int64, bool) { return int64(t.v), t.v <= math.MaxInt64 }
+func (t uintValue) ToInt() int64 { if t.v > math.MaxInt64 { return 0 }; return int64(t.v) }
+func (t uintValue) Uint() (uint64, bool) { return t.v, true }
+func (t uintValue) ToUint() uint64 { return t.v }
+func (t uintValue) Float() (float64, bool) { return float64(t.v), true }
+func (t uintValue) ToFloat() float64 { return float64(t.v) }
+func (t uintValue) Format(f fmt.State, verb rune) { formatValue(f, verb, t.raw, t.v) }
+
+// Pre-converted floating point argument.
+type floatValue struct {
+  raw string
+  v   float64
+}
+
+func (t floatValue) String() (string, bool) { return t.raw, true }
+func (t floatValue) ToString() string { return t.raw }
+func (t floatValue) Bool() (bool, bool) { return t.v != 0.0, true }
+func (t floatValue) ToBool() bool { return t.v != 0.0 }
+func (t floatValue) Int() (int64, bool) { return int64(t.v), true }
+func (t floatValue) ToInt() int64 { return int64(t.v) }
+func (t floatValue) Uint() (uint64, bool) { return uint64(t.v), t.v >= 0.0 }
+func (t floatValue) ToUint() uint64 { if t.v < 0.0 { return 0 }; return uint64(t.v) }
+func (t floatValue) Float() (float64, bool) { return t.v, true }
+func (t floatValue) ToFloat() float64 { return t.v }
+func (t floatValue) Format(f fmt.State, verb rune) { formatValue(f, verb, t.raw, t.v) }
+
+// Pre-converted boolean argument.
+type boolValue struct {
+  raw string
+  v   bool
+}
+
+func (t boolValue) String() (string, bool) { return t.raw, true }
+func (t boolValue) ToString() string { return t.raw }
+func (t boolValue) Bool() (bool, bool) { return t.v, true }
+func (t boolValue) ToBool() bool { return t.v }
+func (t boolValue) Int() (int64, bool) { if t.v { return 1, true }; return 0, true }
+func (t boolValue) ToInt() int64 { if t.v { return 1 }; return 0 }
+func (t boolValue) Uint() (uint64, bool) { if t.v { return 1, true }; return 0, true }
+func (t boolValue) ToUint() uint64 { if t.v { return 1 }; return 0 }
+func (t boolValue) Float() (float64, bool) { if t.v { return 1.0, true }; return 0.0, true }
+func (t boolValue) ToFloat() float64 { if t.v { return 1.0 }; return 0.0 }
+func (t boolValue) Format(f fmt.State, verb rune) { formatValue(f, verb, t.raw, t.v) }
+
+
+// Used internally. Formats pre-converted arguments as specified at the command line. Verbs other than %v, %s and %q
+// format the converted value.
+func formatValue(f fmt.State, verb rune, raw string, value interface{}) {
+  switch verb {
+    case 'v', 's', 'q': fmt.Fprintf(f, fmt.FormatString(f, verb), raw)
+    default:            fmt.Fprintf(f, fmt.FormatString(f, verb), value)
+  }
+}
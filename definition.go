@@ -0,0 +1,265 @@
+package cmdargs
+
+import (
+  "errors"
+  "fmt"
+  "strings"
+  "unicode"
+)
+
+var (
+  // ErrEmptyName is reported by DefineParameter and Validate for names and aliases without content.
+  ErrEmptyName = errors.New("empty name")
+  // ErrInvalidName is reported by DefineParameter and Validate for names and aliases containing invalid characters.
+  ErrInvalidName = errors.New("invalid characters in name")
+  // ErrNameConflict is reported by DefineParameter and Validate for names and aliases that are used by more than
+  // one parameter definition.
+  ErrNameConflict = errors.New("name is already in use")
+  // ErrInvalidArgs is reported by DefineParameter and Validate for a negative number of option arguments and for
+  // settings that refer to more option arguments than expected by the parameter.
+  ErrInvalidArgs = errors.New("invalid number of option arguments")
+  // ErrUnknownParameter is reported by RenameParameter if the parameter definition doesn't exist.
+  ErrUnknownParameter = errors.New("parameter doesn't exist")
+)
+
+// DefinitionError is returned by Parameter.DefineParameter, Parameter.RenameParameter and Parameter.Validate for
+// invalid parameter definitions.
+// Use errors.Is to check for the underlying error, e.g. ErrNameConflict.
+type DefinitionError struct {
+  // Command is the name of the subcommand containing the parameter definition, or empty for the top level.
+  // Names of nested subcommands are separated by spaces.
+  Command   string
+  // Name is the normalized primary name of the affected parameter.
+  Name      string
+  // Alias is the normalized name or alias that caused the error. It may be empty.
+  Alias     string
+  // Err describes the problem.
+  Err       error
+}
+
+// Error returns a textual representation of the error.
+func (e *DefinitionError) Error() string {
+  s := fmt.Sprintf("Invalid definition of parameter \"%s\"", e.Name)
+  if len(e.Command) > 0 {
+    s += fmt.Sprintf(" of command \"%s\"", e.Command)
+  }
+  if len(e.Alias) > 0 && e.Alias != e.Name {
+    s += fmt.Sprintf(" (alias \"%s\")", e.Alias)
+  }
+  if e.Err != nil {
+    s += ": " + e.Err.Error()
+  }
+  return s
+}
+
+// Unwrap returns the underlying error, if available.
+func (e *DefinitionError) Unwrap() error {
+  return e.Err
+}
+
+// DefinitionErrorList is returned by Parameter.Validate if more than one problem was found.
+type DefinitionErrorList []*DefinitionError
+
+// Error returns the textual representations of all errors, separated by newlines.
+func (list DefinitionErrorList) Error() string {
+  s := make([]string, len(list))
+  for i, e := range list {
+    s[i] = e.Error()
+  }
+  return strings.Join(s, "\n")
+}
+
+// Unwrap returns the errors in the list.
+func (list DefinitionErrorList) Unwrap() []error {
+  retVal := make([]error, len(list))
+  for i, e := range list {
+    retVal[i] = e
+  }
+  return retVal
+}
+
+// DefineParameter adds a new parameter definition as described for AddParameter, but doesn't modify existing
+// definitions. Names and aliases must not be empty and must not start with a hyphen after the prefix has been
+// stripped. They must not contain whitespace, control characters, quotes, backslashes or equal signs.
+//
+// Returns a *DefinitionError if the name or an alias is invalid or already in use, or if numArgs is negative.
+// The parameter is not added in this case.
+func (param *Parameter) DefineParameter(name string, aliases []string, numArgs int) error {
+  name = getOptionName(name)
+  if err := checkOptionName(name); err != nil { return &DefinitionError{Name: name, Alias: name, Err: err} }
+  if _, ok := param.aliases[name]; ok { return &DefinitionError{Name: name, Alias: name, Err: ErrNameConflict} }
+  if numArgs < 0 {
+    return &DefinitionError{Name: name, Err: fmt.Errorf("%w: %d", ErrInvalidArgs, numArgs)}
+  }
+
+  aliases = getOptionNames(aliases)
+  for i, a := range aliases {
+    if err := checkOptionName(a); err != nil { return &DefinitionError{Name: name, Alias: a, Err: err} }
+    conflict := (a == name)
+    if _, ok := param.aliases[a]; ok { conflict = true }
+    for _, b := range aliases[:i] {
+      if a == b { conflict = true }
+    }
+    if conflict { return &DefinitionError{Name: name, Alias: a, Err: ErrNameConflict} }
+  }
+
+  param.AddParameter(name, aliases, numArgs)
+  return nil
+}
+
+// Validate checks all parameter definitions, including the definitions of subcommands, and reports every problem
+// at once. Names and aliases are checked as described for DefineParameter. Names and aliases must refer to a single
+// parameter, which also applies to aliases that were reassigned by AddParameter. Option argument types, validators,
+// placeholder names and default values must not refer to more option arguments than expected by the parameter.
+//
+// Returns nil if no problems were found, a single *DefinitionError, or a DefinitionErrorList.
+func (param *Parameter) Validate() error {
+  list := param.validate("", nil)
+  switch len(list) {
+    case 0:   return nil
+    case 1:   return list[0]
+    default:  return list
+  }
+}
+
+// Definition provides read-only access to a parameter definition. It always reflects the current state of the
+// definition, including changes made after it was retrieved.
+type Definition struct {
+  def       *paramType  // The referenced parameter definition
+}
+
+// Definitions returns all parameter definitions in registration order.
+func (param *Parameter) Definitions() []Definition {
+  retVal := make([]Definition, len(param.defs))
+  for i, def := range param.defs {
+    retVal[i] = Definition{def: def}
+  }
+  return retVal
+}
+
+// Lookup returns the parameter definition referenced by the given name or alias. Prefix "-" or "--" may be omitted.
+//
+// The second return value indicates whether a parameter definition of given name exists. The returned Definition
+// must not be used otherwise.
+func (param *Parameter) Lookup(alias string) (Definition, bool) {
+  def, ok := param.aliases[getOptionName(alias)]
+  return Definition{def: def}, ok
+}
+
+// Name returns the normalized primary name of the parameter, i.e. without prefix.
+func (d Definition) Name() string {
+  return d.def.name
+}
+
+// Aliases returns the normalized alternate names of the parameter in registration order.
+func (d Definition) Aliases() []string {
+  return append([]string{}, d.def.aliases...)
+}
+
+// NumArgs returns the number of option arguments expected by the parameter.
+func (d Definition) NumArgs() int {
+  return d.def.numArgs
+}
+
+// Types returns the declared types of the option arguments. Arguments without declared type are of type TypeString.
+func (d Definition) Types() []ValueType {
+  retVal := make([]ValueType, d.def.numArgs)
+  copy(retVal, d.def.types)
+  return retVal
+}
+
+// Description returns the description of the parameter.
+func (d Definition) Description() string {
+  return d.def.desc
+}
+
+// Metavars returns the placeholder names of the option arguments as shown in the usage output.
+func (d Definition) Metavars() []string {
+  return d.def.getMetavars()
+}
+
+// Group returns the name of the section in the usage output, or an empty string for the "Options" section.
+func (d Definition) Group() string {
+  return d.def.group
+}
+
+// Default returns the default values of the option arguments as shown in the usage output.
+func (d Definition) Default() []string {
+  return append([]string{}, d.def.defValue...)
+}
+
+// Env returns the name of the bound environment variable, or an empty string if not available.
+func (d Definition) Env() string {
+  return d.def.env
+}
+
+// Choices returns the allowed choices for the option argument at the specified index as defined by ChoiceValidator
+// instances. A negative index returns the choices for all arguments. Returns nil if no choices are defined.
+func (d Definition) Choices(index int) []string {
+  return d.def.getChoices(index)
+}
+
+// Completion returns the completion hint for the option arguments as defined by SetParameterCompletion.
+func (d Definition) Completion() CompletionHint {
+  return d.def.hint
+}
+
+// IsHelp returns whether the parameter was added by AddHelpParameter.
+func (d Definition) IsHelp() bool {
+  return d.def.kind == paramHelp
+}
+
+// IsVersion returns whether the parameter was added by AddVersionParameter.
+func (d Definition) IsVersion() bool {
+  return d.def.kind == paramVersion
+}
+
+
+// Used internally. Appends the problems of the parameter definitions to list. command is the name of the
+// subcommand.
+func (param *Parameter) validate(command string, list DefinitionErrorList) DefinitionErrorList {
+  for _, def := range param.defs {
+    for _, a := range append([]string{def.name}, def.aliases...) {
+      if err := checkOptionName(a); err != nil {
+        list = append(list, &DefinitionError{Command: command, Name: def.name, Alias: a, Err: err})
+      } else if param.aliases[a] != def {
+        list = append(list, &DefinitionError{Command: command, Name: def.name, Alias: a, Err: ErrNameConflict})
+      }
+    }
+    for _, a := range def.taken {
+      if other, ok := param.aliases[a]; ok && other != def {
+        list = append(list, &DefinitionError{Command: command, Name: def.name, Alias: a, Err: ErrNameConflict})
+      }
+    }
+
+    numArgs := len(def.types)
+    for _, n := range []int{len(def.metavars), len(def.defValue)} {
+      if n > numArgs { numArgs = n }
+    }
+    for _, e := range def.validators {
+      if e.index >= numArgs { numArgs = e.index + 1 }
+    }
+    if numArgs > def.numArgs {
+      list = append(list, &DefinitionError{Command: command, Name: def.name,
+                                           Err: fmt.Errorf("%w: settings for %d option arguments, expected %d",
+                                                           ErrInvalidArgs, numArgs, def.numArgs)})
+    }
+  }
+
+  for _, c := range param.commands {
+    list = c.param.validate(strings.TrimSpace(command + " " + c.name), list)
+  }
+  return list
+}
+
+// Used internally. Returns an error if the given normalized name or alias is not valid.
+func checkOptionName(name string) error {
+  if len(name) == 0 { return ErrEmptyName }
+  if name[0] == '-' { return ErrInvalidName }
+  for _, ch := range name {
+    if unicode.IsSpace(ch) || unicode.IsControl(ch) || strings.ContainsRune("=\"'\\`", ch) {
+      return ErrInvalidName
+    }
+  }
+  return nil
+}
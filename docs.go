@@ -0,0 +1,247 @@
+package cmdargs
+
+import (
+  "html"
+  "io"
+  "strings"
+)
+
+// WriteMarkdown writes a reference page in Markdown format generated from the parameter definitions to w.
+//
+// The page consists of the usage synopsis, the application description, option tables, subcommands and examples.
+// Each option and subcommand is given an anchor, e.g. "tool--cmd-build" for the subcommand "build" of "tool" and
+// "tool--cmd-build--opt-force" for the option "--force" of the subcommand. Subcommands are documented in sections
+// of their own and are cross-linked with the parent command.
+func (param *Parameter) WriteMarkdown(w io.Writer) error {
+  var sb strings.Builder
+  prog := param.getProgramName()
+  param.writeMarkdown(&sb, prog, getAnchor(prog), 1)
+  _, err := io.WriteString(w, sb.String())
+  return err
+}
+
+// WriteHTML writes a self-contained reference page in HTML format generated from the parameter definitions to w.
+//
+// The page provides the same content and anchors as WriteMarkdown.
+func (param *Parameter) WriteHTML(w io.Writer) error {
+  var sb strings.Builder
+  prog := param.getProgramName()
+  sb.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+  sb.WriteString("<title>" + html.EscapeString(prog) + "</title>\n")
+  sb.WriteString("<style>\n" +
+                 "body { font-family: sans-serif; max-width: 60em; margin: auto; padding: 1em; }\n" +
+                 "pre, code { font-family: monospace; }\n" +
+                 "pre { background: #f4f4f4; padding: 0.5em; }\n" +
+                 "table { border-collapse: collapse; }\n" +
+                 "th, td { border: 1px solid #ccc; padding: 0.25em 0.5em; text-align: left; vertical-align: top; }\n" +
+                 "</style>\n")
+  sb.WriteString("</head>\n<body>\n")
+  param.writeHTML(&sb, prog, getAnchor(prog), 1)
+  sb.WriteString("</body>\n</html>\n")
+  _, err := io.WriteString(w, sb.String())
+  return err
+}
+
+
+// Used internally. Writes the Markdown documentation of the command and its subcommands. anchor is the anchor of
+// the command.
+func (param *Parameter) writeMarkdown(sb *strings.Builder, prog string, anchor string, level int) {
+  sb.WriteString(markdownHeading(level) + " <a id=\"" + anchor + "\"></a>" + markdownEscape(prog) + "\n\n")
+  if param.parent != nil {
+    parentProg := strings.TrimSuffix(prog, " " + param.cmdName)
+    sb.WriteString("Subcommand of [" + markdownEscape(parentProg) + "](#" + getParentAnchor(anchor) + ").\n\n")
+  }
+  if len(param.progDesc) > 0 {
+    sb.WriteString(markdownEscape(strings.TrimSpace(param.progDesc)) + "\n\n")
+  }
+
+  sb.WriteString(markdownHeading(level + 1) + " Usage\n\n")
+  sb.WriteString("```\n" + strings.Join(append([]string{prog}, param.getSynopsis()...), " ") + "\n```\n\n")
+
+  for _, group := range param.getGroups() {
+    if !param.hasGroup(group) { continue }
+    if len(group) > 0 {
+      sb.WriteString(markdownHeading(level + 1) + " " + markdownEscape(group) + "\n\n")
+    } else {
+      sb.WriteString(markdownHeading(level + 1) + " Options\n\n")
+    }
+    sb.WriteString("| Option | Description |\n| --- | --- |\n")
+    for _, def := range param.defs {
+      if def.group != group { continue }
+      names := def.getNames()
+      for i := range names {
+        names[i] = "`" + names[i] + "`"
+      }
+      cell := "<a id=\"" + getOptionAnchor(anchor, def.name) + "\"></a>" + strings.Join(names, ", ")
+      if metavars := def.getMetavars(); len(metavars) > 0 {
+        cell += " `" + strings.Join(metavars, " ") + "`"
+      }
+      sb.WriteString("| " + cell + " | " + markdownCell(def.getUsageText()) + " |\n")
+    }
+    sb.WriteString("\n")
+  }
+
+  if len(param.commands) > 0 {
+    sb.WriteString(markdownHeading(level + 1) + " Commands\n\n| Command | Description |\n| --- | --- |\n")
+    for _, c := range param.commands {
+      sb.WriteString("| [" + markdownEscape(c.name) + "](#" + getCommandAnchor(anchor, c.name) + ") | " +
+                     markdownCell(c.param.progDesc) + " |\n")
+    }
+    sb.WriteString("\n")
+  }
+
+  if len(param.examples) > 0 {
+    sb.WriteString(markdownHeading(level + 1) + " Examples\n\n")
+    for _, e := range param.examples {
+      if len(e.desc) > 0 {
+        sb.WriteString(markdownEscape(strings.TrimSpace(e.desc)) + "\n\n")
+      }
+      sb.WriteString("```\n" + e.cmdLine + "\n```\n\n")
+    }
+  }
+
+  for _, c := range param.commands {
+    c.param.writeMarkdown(sb, prog + " " + c.name, getCommandAnchor(anchor, c.name), level + 1)
+  }
+}
+
+// Used internally. Writes the HTML documentation of the command and its subcommands. anchor is the anchor of the
+// command.
+func (param *Parameter) writeHTML(sb *strings.Builder, prog string, anchor string, level int) {
+  sb.WriteString(htmlHeading(level, " id=\"" + anchor + "\"", html.EscapeString(prog)))
+  if param.parent != nil {
+    parentProg := strings.TrimSuffix(prog, " " + param.cmdName)
+    sb.WriteString("<p>Subcommand of <a href=\"#" + getParentAnchor(anchor) + "\">" + html.EscapeString(parentProg) +
+                   "</a>.</p>\n")
+  }
+  if len(param.progDesc) > 0 {
+    sb.WriteString(htmlParagraphs(param.progDesc))
+  }
+
+  sb.WriteString(htmlHeading(level + 1, "", "Usage"))
+  sb.WriteString("<pre>" + html.EscapeString(strings.Join(append([]string{prog}, param.getSynopsis()...), " ")) +
+                 "</pre>\n")
+
+  for _, group := range param.getGroups() {
+    if !param.hasGroup(group) { continue }
+    if len(group) > 0 {
+      sb.WriteString(htmlHeading(level + 1, "", html.EscapeString(group)))
+    } else {
+      sb.WriteString(htmlHeading(level + 1, "", "Options"))
+    }
+    sb.WriteString("<table>\n<tr><th>Option</th><th>Description</th></tr>\n")
+    for _, def := range param.defs {
+      if def.group != group { continue }
+      names := def.getNames()
+      for i := range names {
+        names[i] = "<code>" + html.EscapeString(names[i]) + "</code>"
+      }
+      cell := strings.Join(names, ", ")
+      if metavars := def.getMetavars(); len(metavars) > 0 {
+        cell += " <var>" + html.EscapeString(strings.Join(metavars, " ")) + "</var>"
+      }
+      sb.WriteString("<tr id=\"" + getOptionAnchor(anchor, def.name) + "\"><td>" + cell + "</td><td>" +
+                     html.EscapeString(def.getUsageText()) + "</td></tr>\n")
+    }
+    sb.WriteString("</table>\n")
+  }
+
+  if len(param.commands) > 0 {
+    sb.WriteString(htmlHeading(level + 1, "", "Commands"))
+    sb.WriteString("<table>\n<tr><th>Command</th><th>Description</th></tr>\n")
+    for _, c := range param.commands {
+      sb.WriteString("<tr><td><a href=\"#" + getCommandAnchor(anchor, c.name) + "\">" + html.EscapeString(c.name) +
+                     "</a></td><td>" + html.EscapeString(strings.TrimSpace(c.param.progDesc)) + "</td></tr>\n")
+    }
+    sb.WriteString("</table>\n")
+  }
+
+  if len(param.examples) > 0 {
+    sb.WriteString(htmlHeading(level + 1, "", "Examples"))
+    for _, e := range param.examples {
+      if len(e.desc) > 0 {
+        sb.WriteString(htmlParagraphs(e.desc))
+      }
+      sb.WriteString("<pre>" + html.EscapeString(e.cmdLine) + "</pre>\n")
+    }
+  }
+
+  for _, c := range param.commands {
+    c.param.writeHTML(sb, prog + " " + c.name, getCommandAnchor(anchor, c.name), level + 1)
+  }
+}
+
+// Used internally. Returns an anchor name for the given text, consisting only of lower-case letters, digits and
+// hyphens.
+func getAnchor(text string) string {
+  var sb strings.Builder
+  hyphen := false
+  for _, ch := range strings.ToLower(text) {
+    if (ch >= 'a' && ch <= 'z') || (ch >= '0' && ch <= '9') {
+      if hyphen && sb.Len() > 0 { sb.WriteByte('-') }
+      sb.WriteRune(ch)
+      hyphen = false
+    } else {
+      hyphen = true
+    }
+  }
+  return sb.String()
+}
+
+// Used internally. Returns the anchor of the given option of the command with the specified anchor. Anchors
+// returned by getAnchor never contain consecutive hyphens, so that the separator can't clash with names.
+func getOptionAnchor(anchor string, name string) string {
+  return anchor + "--opt-" + getAnchor(name)
+}
+
+// Used internally. Returns the anchor of the given subcommand of the command with the specified anchor.
+func getCommandAnchor(anchor string, name string) string {
+  return anchor + "--cmd-" + getAnchor(name)
+}
+
+// Used internally. Returns the anchor of the parent command of the subcommand with the specified anchor.
+func getParentAnchor(anchor string) string {
+  if i := strings.LastIndex(anchor, "--cmd-"); i >= 0 { return anchor[:i] }
+  return anchor
+}
+
+// Used internally. Returns the Markdown heading prefix for the given level.
+func markdownHeading(level int) string {
+  if level > 6 { level = 6 }
+  return strings.Repeat("#", level)
+}
+
+// Used internally. Escapes characters with special meaning in Markdown text.
+func markdownEscape(s string) string {
+  var sb strings.Builder
+  for _, ch := range s {
+    if strings.ContainsRune("\\`*_[]<>|#", ch) {
+      sb.WriteByte('\\')
+    }
+    sb.WriteRune(ch)
+  }
+  return sb.String()
+}
+
+// Used internally. Returns the given text as content of a single Markdown table cell.
+func markdownCell(s string) string {
+  return strings.Join(strings.Fields(markdownEscape(s)), " ")
+}
+
+// Used internally. Returns an HTML heading of the given level.
+func htmlHeading(level int, attr string, content string) string {
+  if level > 6 { level = 6 }
+  tag := "h" + string(rune('0' + level))
+  return "<" + tag + attr + ">" + content + "</" + tag + ">\n"
+}
+
+// Used internally. Returns the given text as HTML paragraphs. Paragraphs are separated by empty lines.
+func htmlParagraphs(text string) string {
+  var sb strings.Builder
+  for _, p := range strings.Split(strings.TrimSpace(text), "\n\n") {
+    if p = strings.TrimSpace(p); len(p) > 0 {
+      sb.WriteString("<p>" + html.EscapeString(p) + "</p>\n")
+    }
+  }
+  return sb.String()
+}
@@ -0,0 +1,62 @@
+package cmdargs
+
+import (
+  "regexp"
+  "strings"
+  "testing"
+)
+
+func TestWriteMarkdown(t *testing.T) {
+  var sb strings.Builder
+  if err := newDocParam().WriteMarkdown(&sb); err != nil {
+    t.Fatalf("WriteMarkdown: %v", err)
+  }
+  checkGolden(t, "tool.md", sb.String())
+  checkAnchors(t, sb.String())
+}
+
+func TestWriteHTML(t *testing.T) {
+  var sb strings.Builder
+  if err := newDocParam().WriteHTML(&sb); err != nil {
+    t.Fatalf("WriteHTML: %v", err)
+  }
+  checkGolden(t, "tool.html", sb.String())
+  checkAnchors(t, sb.String())
+}
+
+// Anchors of options and subcommands with similar names must be unique.
+func TestDocAnchors(t *testing.T) {
+  param := Create()
+  param.SetProgramName("tool")
+  param.AddParameter("build", nil, 0)
+  param.AddParameter("build-force", nil, 0)
+  build := Create()
+  build.AddParameter("force", nil, 0)
+  param.AddCommand("build", build)
+
+  var sb strings.Builder
+  param.WriteMarkdown(&sb)
+  checkAnchors(t, sb.String())
+  sb.Reset()
+  param.WriteHTML(&sb)
+  checkAnchors(t, sb.String())
+  for _, id := range []string{"tool--opt-build", "tool--opt-build-force", "tool--cmd-build",
+                              "tool--cmd-build--opt-force"} {
+    if !strings.Contains(sb.String(), "id=\"" + id + "\"") {
+      t.Errorf("anchor %q is missing", id)
+    }
+  }
+}
+
+// Used internally. Reports duplicate anchors and links to missing anchors in the given document.
+func checkAnchors(t *testing.T, doc string) {
+  t.Helper()
+  ids := make(map[string]bool)
+  for _, m := range regexp.MustCompile(`id="([^"]*)"`).FindAllStringSubmatch(doc, -1) {
+    if ids[m[1]] { t.Errorf("duplicate anchor %q", m[1]) }
+    ids[m[1]] = true
+  }
+  for _, m := range regexp.MustCompile(`(?:\(|href=")#([^")]*)`).FindAllStringSubmatch(doc, -1) {
+    if !ids[m[1]] { t.Errorf("link to missing anchor %q", m[1]) }
+  }
+}
@@ -0,0 +1,441 @@
+package cmdargs
+
+import (
+  "errors"
+  "io"
+  "os"
+  "path/filepath"
+  "strconv"
+  "strings"
+)
+
+// Name of the hidden entry point for dynamic completion.
+const completeCommand = "__complete"
+
+// ErrComplete is returned by Parameter.Evaluate if the hidden completion entry point enabled by EnableCompletion
+// was invoked.
+var ErrComplete = errors.New("completion requested")
+
+// Completion describes a single completion candidate.
+type Completion struct {
+  // Value is the candidate that is inserted at the command line.
+  Value       string
+  // Description is an optional one-line description of the candidate.
+  Description string
+}
+
+// CompleteFunc returns completion candidates for the option argument at the specified index. prefix contains the
+// partial argument that is to be completed. Candidates that don't start with prefix are discarded, so the function
+// may return all available candidates.
+type CompleteFunc func(index int, prefix string) []Completion
+
+// SetParameterCompleter registers a function that provides completion candidates for the arguments of the parameter
+// specified by "name". Completion functions are only considered by dynamic completion. See EnableCompletion for
+// details.
+//
+// Returns whether a parameter definition of given name exists.
+func (param *Parameter) SetParameterCompleter(name string, fn CompleteFunc) bool {
+  p, ok := param.aliases[getOptionName(name)]
+  if ok {
+    p.completer = fn
+  }
+  return ok
+}
+
+// EnableCompletion enables dynamic completion.
+//
+// If the application is invoked as "<application> __complete <args...>", Evaluate treats the last argument as the
+// partial command line argument to be completed, writes the completion candidates to the output defined by
+// SetOutput and returns ErrComplete. Each candidate is written on a separate line, optionally followed by a tab
+// character and a description. The last line consists of a colon followed by "file", "dir" or "none", which
+// indicates whether the shell should complete file names, directory names or nothing in addition to the candidates.
+//
+// Completion scripts generated by WriteBashCompletion, WriteZshCompletion and WriteFishCompletion delegate to the
+// application if dynamic completion is enabled.
+//
+// Evaluate also supports program-driven completion as used by the bash command "complete -C" and by scripts
+// generated by WritePowerShellCompletion: If the application is invoked as "<application> <command> <word>
+// <previous word>" and the environment variables COMP_LINE and COMP_POINT are defined, the command line is taken
+// from COMP_LINE up to the cursor position in COMP_POINT. COMP_LINE must start with <command>. Empty words may be
+// omitted. The environment variables are ignored for other command lines. Evaluate writes the completion
+// candidates, one per line and without descriptions, including matching file or directory names, and returns
+// ErrComplete.
+func (param *Parameter) EnableCompletion() {
+  param.dynamic = true
+}
+
+// Complete returns the completion candidates for the given partial command line arguments, excluding the
+// application name. The last argument is the partial argument to be completed. Completion candidates are considered
+// in addition to the returned completion hint, which is never CompleteDefault.
+func (param *Parameter) Complete(args []string) (candidates []Completion, hint CompletionHint) {
+  args = joinAssignments(args)
+  cur := ""
+  if len(args) > 0 {
+    cur = args[len(args) - 1]
+    args = args[:len(args) - 1]
+  }
+
+  p := param
+  var def *paramType
+  need, argi, pos, ended := 0, 0, 0, false
+  for _, w := range args {
+    if need > 0 { need--; argi++; continue }
+    if ended { pos++; continue }
+    if isOption(w) {
+      name, _, attached := strings.Cut(w, "=")
+      def = p.aliases[getOptionName(name)]
+      need, argi = 0, 0
+      if def != nil {
+        need = def.numArgs
+        if attached && need > 0 { need--; argi = 1 }
+      }
+    } else if c := p.getCommand(w); c != nil {
+      p = c.param
+    } else {
+      ended, pos = true, 1
+    }
+  }
+
+  switch {
+    case need > 0:
+      return def.complete(argi, cur)
+    case ended:
+      return p.completePositional(pos, cur)
+    case isOption(cur) || cur == "-":
+      if name, value, attached := strings.Cut(cur, "="); attached {
+        if def, ok := p.aliases[getOptionName(name)]; ok && def.numArgs > 0 {
+          return def.complete(0, value)
+        }
+        return nil, CompleteNone
+      }
+      for _, def := range p.defs {
+        for _, name := range def.getNames() {
+          if strings.HasPrefix(name, cur) {
+            candidates = append(candidates, Completion{Value: name, Description: getSummary(def.desc)})
+          }
+        }
+      }
+      return candidates, CompleteNone
+    case len(p.commands) > 0:
+      for _, c := range p.commands {
+        if strings.HasPrefix(c.name, cur) {
+          candidates = append(candidates, Completion{Value: c.name, Description: getSummary(c.param.progDesc)})
+        }
+      }
+      return candidates, CompleteNone
+    default:
+      return p.completePositional(0, cur)
+  }
+}
+
+
+// WriteBashCompleteCommand writes the bash command that registers the application for program-driven completion
+// to w, i.e. "complete -C <application> <application>". Dynamic completion must be enabled by EnableCompletion.
+func (param *Parameter) WriteBashCompleteCommand(w io.Writer) error {
+  prog := param.getProgramName()
+  _, err := io.WriteString(w, "complete -C " + shellQuote(prog) + " " + shellQuote(prog) + "\n")
+  return err
+}
+
+
+// Used internally. Returns the command line to complete as defined by the environment variables COMP_LINE and
+// COMP_POINT if args is in the form used by "complete -C", i.e. application, command, word and previous word.
+func getCompletionLine(args []string) (line string, ok bool) {
+  if len(args) < 2 || len(args) > 4 || len(args[1]) == 0 { return }
+  line, ok = os.LookupEnv("COMP_LINE")
+  if !ok || !strings.HasPrefix(line, args[1]) { return "", false }
+  point, err := strconv.Atoi(os.Getenv("COMP_POINT"))
+  if err != nil { ok = false; return }
+  if point >= 0 && point < len(line) {
+    line = line[:point]
+  }
+  return
+}
+
+// Used internally. Writes completion candidates for the given command line in the format expected by
+// "complete -C".
+func (param *Parameter) writeCompletionLine(w io.Writer, line string) error {
+  args, _ := splitCommandLine(line)
+  if len(args) > 0 {
+    args = args[1:]   // skipping application name
+  }
+  if len(line) == 0 || strings.ContainsRune(" \t", rune(line[len(line) - 1])) {
+    args = append(args, "")
+  }
+
+  var sb strings.Builder
+  candidates, hint := param.Complete(args)
+  for _, c := range candidates {
+    sb.WriteString(c.Value + "\n")
+  }
+  if hint != CompleteNone {
+    cur := ""
+    if len(args) > 0 { cur = args[len(args) - 1] }
+    if isOption(cur) {
+      if _, value, attached := strings.Cut(cur, "="); attached { cur = value }
+    }
+    for _, path := range getMatchingPaths(cur, hint == CompleteDir) {
+      sb.WriteString(path + "\n")
+    }
+  }
+  _, err := io.WriteString(w, sb.String())
+  return err
+}
+
+// Used internally. Returns all file and directory names starting with the given prefix. Directory names are
+// returned with a trailing path separator.
+func getMatchingPaths(prefix string, dirOnly bool) []string {
+  retVal := make([]string, 0)
+  var sb strings.Builder
+  for _, ch := range prefix {
+    if strings.ContainsRune("*?[\\", ch) { sb.WriteByte('\\') }
+    sb.WriteRune(ch)
+  }
+  matches, err := filepath.Glob(sb.String() + "*")
+  if err != nil { return retVal }
+  for _, m := range matches {
+    fi, err := os.Stat(m)
+    if err != nil { continue }
+    if fi.IsDir() {
+      retVal = append(retVal, m + string(filepath.Separator))
+    } else if !dirOnly {
+      retVal = append(retVal, m)
+    }
+  }
+  return retVal
+}
+
+// Used internally. Splits the given command line into arguments. Arguments are separated by whitespace. Single and
+// double quotes and backslash escapes are handled as in POSIX shells. Returns whether the command line ends within
+// a quoted string or escape sequence.
+func splitCommandLine(line string) (args []string, open bool) {
+  args = make([]string, 0)
+  var sb strings.Builder
+  inArg := false
+  var quote rune
+  escape := false
+  for _, ch := range line {
+    switch {
+      case escape:
+        sb.WriteRune(ch)
+        escape = false
+      case quote == '\'':
+        if ch == '\'' { quote = 0 } else { sb.WriteRune(ch) }
+      case quote == '"':
+        if ch == '"' {
+          quote = 0
+        } else if ch == '\\' {
+          escape = true
+        } else {
+          sb.WriteRune(ch)
+        }
+      case ch == '\\':
+        escape = true
+        inArg = true
+      case ch == '\'' || ch == '"':
+        quote = ch
+        inArg = true
+      case ch == ' ' || ch == '\t' || ch == '\n':
+        if inArg {
+          args = append(args, sb.String())
+          sb.Reset()
+          inArg = false
+        }
+      default:
+        sb.WriteRune(ch)
+        inArg = true
+    }
+  }
+  if inArg {
+    args = append(args, sb.String())
+  }
+  open = escape || quote != 0
+  return
+}
+
+// Used internally. Writes the completion candidates for the given partial command line arguments.
+func (param *Parameter) writeCompletion(w io.Writer, args []string) error {
+  var sb strings.Builder
+  candidates, hint := param.Complete(args)
+  for _, c := range candidates {
+    sb.WriteString(c.Value)
+    if len(c.Description) > 0 {
+      sb.WriteString("\t" + c.Description)
+    }
+    sb.WriteString("\n")
+  }
+  switch hint {
+    case CompleteDir:   sb.WriteString(":dir\n")
+    case CompleteNone:  sb.WriteString(":none\n")
+    default:            sb.WriteString(":file\n")
+  }
+  _, err := io.WriteString(w, sb.String())
+  return err
+}
+
+// Used internally. Returns completion candidates for the option argument at the specified index.
+func (def *paramType) complete(index int, prefix string) ([]Completion, CompletionHint) {
+  if def.completer != nil {
+    candidates := def.completer(index, prefix)
+    retVal := make([]Completion, 0, len(candidates))
+    for _, c := range candidates {
+      if strings.HasPrefix(c.Value, prefix) {
+        retVal = append(retVal, c)
+      }
+    }
+    return retVal, CompleteNone
+  }
+
+  choices, hint := def.getCompletion(index)
+  return completeChoices(choices, hint, prefix)
+}
+
+// Used internally. Returns completion candidates for the extra argument at the specified index.
+func (param *Parameter) completePositional(index int, prefix string) ([]Completion, CompletionHint) {
+  if p := param.getPositionalAt(index); p != nil {
+    return p.complete(prefix)
+  }
+  return nil, CompleteFile
+}
+
+// Used internally. Returns the given choices that start with prefix as completion candidates, or the given
+// completion hint if no choices are defined.
+func completeChoices(choices []string, hint CompletionHint, prefix string) ([]Completion, CompletionHint) {
+  if len(choices) > 0 {
+    candidates := make([]Completion, 0, len(choices))
+    for _, c := range choices {
+      if strings.HasPrefix(c, prefix) {
+        candidates = append(candidates, Completion{Value: c})
+      }
+    }
+    return candidates, CompleteNone
+  }
+  if hint == CompleteDefault { hint = CompleteFile }
+  return nil, hint
+}
+
+// Used internally. Joins stand-alone equal signs with the surrounding arguments, since some shells treat them as
+// separate words.
+func joinAssignments(args []string) []string {
+  retVal := make([]string, 0, len(args))
+  join := false
+  for _, a := range args {
+    switch {
+      case a == "=" && len(retVal) > 0 && isOption(retVal[len(retVal) - 1]) &&
+           !strings.Contains(retVal[len(retVal) - 1], "="):
+        retVal[len(retVal) - 1] += a
+        join = true
+      case join:
+        retVal[len(retVal) - 1] += a
+        join = false
+      default:
+        retVal = append(retVal, a)
+    }
+  }
+  return retVal
+}
+
+// Used internally. Writes a bash completion script that delegates to the application.
+func (param *Parameter) writeBashDelegate(sb *strings.Builder, prog string) {
+  fn := "_" + getIdentifier(prog) + "_complete"
+  sb.WriteString("# bash completion for " + prog + "\n")
+  sb.WriteString(fn + "() {\n")
+  sb.WriteString("  local cur=\"${COMP_WORDS[COMP_CWORD]}\" line hint=file\n")
+  sb.WriteString("  COMPREPLY=()\n")
+  sb.WriteString("  [[ $cur == = ]] && cur=''\n")
+  sb.WriteString("  while IFS= read -r line; do\n")
+  sb.WriteString("    case \"$line\" in\n")
+  sb.WriteString("      :*) hint=\"${line#:}\" ;;\n")
+  sb.WriteString("      *) COMPREPLY+=(\"${line%%$'\\t'*}\") ;;\n")
+  sb.WriteString("    esac\n")
+  sb.WriteString("  done < <(\"${COMP_WORDS[0]}\" " + completeCommand +
+                 " \"${COMP_WORDS[@]:1:COMP_CWORD-1}\" \"$cur\" 2>/dev/null)\n")
+  sb.WriteString("  case \"$hint\" in\n")
+  sb.WriteString("    file) COMPREPLY+=($(compgen -f -- \"$cur\")) ;;\n")
+  sb.WriteString("    dir) COMPREPLY+=($(compgen -d -- \"$cur\")) ;;\n")
+  sb.WriteString("  esac\n")
+  sb.WriteString("  return 0\n")
+  sb.WriteString("}\n")
+  sb.WriteString("complete -F " + fn + " " + shellQuote(prog) + "\n")
+}
+
+// Used internally. Writes a zsh completion script that delegates to the application.
+func (param *Parameter) writeZshDelegate(sb *strings.Builder, prog string) {
+  fn := "_" + getIdentifier(prog)
+  sb.WriteString("#compdef " + prog + "\n\n")
+  sb.WriteString(fn + "() {\n")
+  sb.WriteString("  local cur=\"${words[CURRENT]}\" pre='' line hint=file\n")
+  sb.WriteString("  local -a values descs\n")
+  sb.WriteString("  [[ $cur == -*=* ]] && pre=\"${cur%%=*}=\"\n")
+  sb.WriteString("  for line in \"${(@f)$(\"${words[1]}\" " + completeCommand +
+                 " \"${(@)words[2,CURRENT]}\" 2>/dev/null)}\"; do\n")
+  sb.WriteString("    [[ -z $line ]] && continue\n")
+  sb.WriteString("    case \"$line\" in\n")
+  sb.WriteString("      :*) hint=\"${line#:}\" ;;\n")
+  sb.WriteString("      *$'\\t'*) values+=(\"${line%%$'\\t'*}\");" +
+                 " descs+=(\"${line%%$'\\t'*} -- ${line#*$'\\t'}\") ;;\n")
+  sb.WriteString("      *) values+=(\"$line\"); descs+=(\"$line\") ;;\n")
+  sb.WriteString("    esac\n")
+  sb.WriteString("  done\n")
+  sb.WriteString("  (( ${#values} )) && compadd -l -P \"$pre\" -d descs -a values\n")
+  sb.WriteString("  case \"$hint\" in\n")
+  sb.WriteString("    file) _files ;;\n")
+  sb.WriteString("    dir) _files -/ ;;\n")
+  sb.WriteString("  esac\n")
+  sb.WriteString("}\n\n")
+  sb.WriteString("if [ \"$funcstack[1]\" = " + shellQuote(fn) + " ]; then\n")
+  sb.WriteString("  " + fn + " \"$@\"\n")
+  sb.WriteString("else\n")
+  sb.WriteString("  compdef " + fn + " " + shellQuote(prog) + "\n")
+  sb.WriteString("fi\n")
+}
+
+// Used internally. Writes a fish completion script that delegates to the application.
+func (param *Parameter) writeFishDelegate(sb *strings.Builder, prog string) {
+  fn := "__" + getIdentifier(prog) + "_complete"
+  sb.WriteString("# fish completion for " + prog + "\n")
+  sb.WriteString("function " + fn + "\n")
+  sb.WriteString("    set -l args (commandline -opc)\n")
+  sb.WriteString("    set -l cur (commandline -ct)\n")
+  sb.WriteString("    set -l cmd $args[1]\n")
+  sb.WriteString("    set -l pre (string match -r -- '^-[^=]*=' $cur)\n")
+  sb.WriteString("    set -l hint file\n")
+  sb.WriteString("    set -e args[1]\n")
+  sb.WriteString("    for line in ($cmd " + completeCommand + " $args $cur 2>/dev/null)\n")
+  sb.WriteString("        switch $line\n")
+  sb.WriteString("            case ':*'\n")
+  sb.WriteString("                set hint (string sub -s 2 -- $line)\n")
+  sb.WriteString("            case '*'\n")
+  sb.WriteString("                printf '%s%s\\n' \"$pre\" $line\n")
+  sb.WriteString("        end\n")
+  sb.WriteString("    end\n")
+  sb.WriteString("    switch $hint\n")
+  sb.WriteString("        case file\n")
+  sb.WriteString("            __fish_complete_path $cur\n")
+  sb.WriteString("        case dir\n")
+  sb.WriteString("            __fish_complete_directories $cur\n")
+  sb.WriteString("    end\n")
+  sb.WriteString("end\n")
+  sb.WriteString("complete -c " + fishQuote(prog) + " -f -a '(" + fn + ")'\n")
+}
+
+// Used internally. Writes the body of a PowerShell argument completer that delegates to the application by
+// providing the command line in COMP_LINE and COMP_POINT.
+func (param *Parameter) writePowerShellDelegate(sb *strings.Builder) {
+  sb.WriteString("    $line = $commandAst.ToString()\n")
+  sb.WriteString("    $point = $cursorPosition - $commandAst.Extent.StartOffset\n")
+  sb.WriteString("    if ($line.Length -lt $point) { $line = $line.PadRight($point) }\n")
+  sb.WriteString("    $env:COMP_LINE = $line\n")
+  sb.WriteString("    $env:COMP_POINT = $point\n")
+  sb.WriteString("    try {\n")
+  sb.WriteString("        $exe = $commandAst.CommandElements[0].Extent.Text\n")
+  sb.WriteString("        $out = @(& $exe $exe $wordToComplete '' 2>$null)\n")
+  sb.WriteString("    } finally {\n")
+  sb.WriteString("        Remove-Item Env:COMP_LINE, Env:COMP_POINT -ErrorAction SilentlyContinue\n")
+  sb.WriteString("    }\n")
+  sb.WriteString("    $pre = ''\n")
+  sb.WriteString("    if ($wordToComplete -match '^(--?[^=]+=)') { $pre = $Matches[1] }\n")
+  sb.WriteString("    $out | Where-Object { $_ } | ForEach-Object {\n")
+  sb.WriteString("        [Management.Automation.CompletionResult]::new($pre + $_, $_, 'ParameterValue', $_)\n")
+  sb.WriteString("    }\n")
+}
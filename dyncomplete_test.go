@@ -0,0 +1,42 @@
+package cmdargs
+
+import (
+  "errors"
+  "strings"
+  "testing"
+)
+
+// COMP_LINE and COMP_POINT must only be considered for command lines in the form used by "complete -C".
+func TestCompletionLine(t *testing.T) {
+  param := Create()
+  param.AddParameter("verbose", []string{"v"}, 0)
+  param.EnableCompletion()
+  var sb strings.Builder
+  param.SetOutput(&sb)
+  t.Setenv("COMP_LINE", "tool --verb")
+  t.Setenv("COMP_POINT", "11")
+
+  for _, args := range [][]string{{"/usr/bin/tool", "tool", "--verb", "tool"}, {"tool", "tool", "--verb"}} {
+    sb.Reset()
+    if _, err := param.Parse(args); !errors.Is(err, ErrComplete) {
+      t.Errorf("Parse(%q) = %v; want ErrComplete", args, err)
+    }
+    if sb.String() != "--verbose\n" {
+      t.Errorf("Parse(%q) printed %q; want \"--verbose\\n\"", args, sb.String())
+    }
+  }
+
+  for _, args := range [][]string{{"tool", "-v"}, {"tool"}, {"tool", "other", "--verb", "tool"},
+                                  {"tool", "tool", "-v", "a", "b"}} {
+    sb.Reset()
+    if _, err := param.Parse(args); err != nil {
+      t.Errorf("Parse(%q) = %v; want nil", args, err)
+    }
+    if sb.Len() > 0 {
+      t.Errorf("Parse(%q) printed %q", args, sb.String())
+    }
+  }
+  if _, err := param.ParseString("tool -v"); err != nil {
+    t.Errorf("ParseString = %v; want nil", err)
+  }
+}
@@ -0,0 +1,120 @@
+package cmdargs
+
+import (
+  "fmt"
+  "strings"
+)
+
+// ErrorKind identifies the category of an evaluation error.
+type ErrorKind int
+
+const (
+  // UnrecognizedOption indicates an option that doesn't match any parameter definition.
+  UnrecognizedOption ErrorKind = iota
+  // MissingValue indicates an option with fewer arguments than required by the parameter definition.
+  MissingValue
+  // InvalidValue indicates an option argument that doesn't match the declared type of the parameter.
+  InvalidValue
+  // ValidationFailed indicates an error reported by a validation function registered by Parameter.AddCheck.
+  ValidationFailed
+  // MissingArgument indicates a positional argument with fewer values than required by its definition.
+  MissingArgument
+  // UnexpectedArgument indicates an extra argument that doesn't match any positional argument definition.
+  UnexpectedArgument
+  // ActionFailed indicates an error reported by an action function registered by Parameter.SetParameterAction.
+  ActionFailed
+)
+
+// EvalError is returned by Parameter.Evaluate if the command line arguments couldn't be evaluated successfully.
+type EvalError struct {
+  // Kind specifies the category of the error.
+  Kind       ErrorKind
+  // Option is the normalized long name of the affected option. It may be empty.
+  Option     string
+  // Positional is the name of the affected positional argument. It may be empty.
+  Positional string
+  // Slot is the placeholder name of the affected option argument as defined by Parameter.SetParameterMetavars.
+  // It may be empty.
+  Slot       string
+  // Index is the index of the affected option argument, or -1 if not available. Unlike Slot, it identifies the
+  // option argument even if several option arguments share the same placeholder name.
+  Index      int
+  // Position is the index of the offending token in the command line arguments list, or -1 if not available.
+  Position   int
+  // Value contains the offending token or option argument.
+  Value      string
+  // Err optionally provides more details about the error.
+  Err        error
+}
+
+
+// Error returns a textual representation of the error.
+func (e *EvalError) Error() string {
+  var s string
+  switch e.Kind {
+    case UnrecognizedOption:
+      s = fmt.Sprintf("Unrecognized option: \"--%s\" or \"-%s\"", e.Value, e.Value)
+    case MissingValue:
+      if len(e.Slot) > 0 {
+        s = fmt.Sprintf("Missing value %s for option \"--%s\"", e.Slot, e.Option)
+      } else {
+        s = fmt.Sprintf("Too few option arguments for \"--%s\"", e.Option)
+      }
+    case InvalidValue:
+      if len(e.Positional) > 0 {
+        s = fmt.Sprintf("Invalid value for argument \"%s\": \"%s\"", e.Positional, e.Value)
+      } else if len(e.Slot) > 0 {
+        s = fmt.Sprintf("Invalid value %s for option \"--%s\": \"%s\"", e.Slot, e.Option, e.Value)
+      } else {
+        s = fmt.Sprintf("Invalid argument for option \"--%s\": \"%s\"", e.Option, e.Value)
+      }
+    case ValidationFailed:
+      if len(e.Option) > 0 {
+        s = fmt.Sprintf("Invalid use of option \"--%s\"", e.Option)
+      } else if e.Err != nil {
+        return e.Err.Error()
+      } else {
+        s = "Validation failed"
+      }
+    case MissingArgument:
+      s = fmt.Sprintf("Missing argument \"%s\"", e.Positional)
+    case UnexpectedArgument:
+      s = fmt.Sprintf("Unexpected argument: \"%s\"", e.Value)
+    case ActionFailed:
+      s = fmt.Sprintf("Failed to process option \"--%s\"", e.Option)
+    default:
+      s = "Evaluation error"
+  }
+  if e.Err != nil {
+    s += ": " + e.Err.Error()
+  }
+  return s
+}
+
+// Unwrap returns the underlying error, if available.
+func (e *EvalError) Unwrap() error {
+  return e.Err
+}
+
+
+// ErrorList is returned by Parameter.Evaluate if more than one validation function failed.
+// Use errors.As to retrieve the first *EvalError in the list.
+type ErrorList []*EvalError
+
+// Error returns the textual representations of all errors, separated by newlines.
+func (list ErrorList) Error() string {
+  s := make([]string, len(list))
+  for i, e := range list {
+    s[i] = e.Error()
+  }
+  return strings.Join(s, "\n")
+}
+
+// Unwrap returns the errors in the list.
+func (list ErrorList) Unwrap() []error {
+  retVal := make([]error, len(list))
+  for i, e := range list {
+    retVal[i] = e
+  }
+  return retVal
+}
@@ -15,7 +15,8 @@ func main() {
   // A simple option with a single alias and no additional arguments. Parameter names and aliases are stored 
   // case-sensitive, but without hyphens internally. So "--A" and "-A" are treated as identical options, but "-A" 
   // and "-a" are not.
-  parameters.AddParameter("help", []string{"h"}, 0)
+  // Help options are handled by Evaluate automatically.
+  parameters.AddHelpParameter("help", []string{"h"})
   // An option without alias that requires one additional argument. Arguments for options are stored as Generic 
   // types internally. You can convert them to various basic datatypes by the available interface functions.
   // To convert a Generic to a string, use either String(), for additional type checking, or ToString().
@@ -28,16 +29,20 @@ func main() {
   // An option that requires two additional arguments.
   parameters.AddParameter("position", nil, 2)
 
-  // Now we parse our command line arguments
-  if err := parameters.Evaluate(os.Args); err != nil {
-    fmt.Printf("Evaluation error: %v\n", err)
-    return
-  }
+  // Descriptions and placeholder names for option arguments are used to generate the usage output.
+  parameters.SetParameterDescription("prefix", "Installation prefix.")
+  parameters.SetParameterMetavars("prefix", "path")
+  parameters.SetParameterDescription("num-threads", "Number of worker threads.")
+  parameters.SetParameterMetavars("num-threads", "n")
+  parameters.SetParameterDescription("position", "Position of the window.")
+  parameters.SetParameterMetavars("position", "x", "y")
 
-  // Checking our options
-  if parameters.GetArgExists("help") {
-    // Print help and exit
-    fmt.Printf("Usage: %s [--help] [--prefix path] [--num-threads n] [--position x y]\n", parameters.GetArgSelf())
+  // Now we parse our command line arguments. If the help option is specified, Evaluate prints the usage output
+  // generated from the parameter definitions and returns ErrHelp.
+  if err := parameters.Evaluate(os.Args); err != nil {
+    if err != cmdargs.ErrHelp {
+      fmt.Printf("Evaluation error: %v\n", err)
+    }
     return
   }
 
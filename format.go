@@ -0,0 +1,197 @@
+package cmdargs
+
+import (
+  "errors"
+  "sort"
+  "strings"
+  "unicode/utf8"
+)
+
+// FormatError returns a compiler-style description of err, which was returned by Evaluate for the given command line
+// arguments. The command line arguments are shown with a marker below the offending argument, followed by a hint,
+// e.g. a suggestion for misspelled options or the expected type or choices of values:
+//
+//   Unrecognized option: "--verbos" or "-verbos"
+//     app --verbos 'my file'
+//         ^~~~~~~~
+//     hint: did you mean "--verbose"?
+//
+// Arguments are quoted as in POSIX shells if needed. The marker is omitted if the position of the error is not
+// available. Errors in an ErrorList are described one after another. Other errors are returned by their textual
+// representation. Use FormatErrorString for errors returned by EvaluateString.
+func (param *Parameter) FormatError(err error, args []string) string {
+  if err == nil { return "" }
+  var list ErrorList
+  if !errors.As(err, &list) {
+    var e *EvalError
+    if !errors.As(err, &e) { return err.Error() }
+    list = ErrorList{e}
+  }
+
+  var sb strings.Builder
+  for _, e := range list {
+    param.formatError(&sb, e, args)
+  }
+  return sb.String()
+}
+
+// FormatErrorString behaves just like FormatError, but takes the command line that was passed to EvaluateString or
+// ParseString instead of the command line arguments. The command line is split as described for SplitCommandLine.
+func (param *Parameter) FormatErrorString(err error, line string) string {
+  args, _ := splitCommandLine(line)
+  return param.FormatError(err, args)
+}
+
+
+// Used internally. Writes the description of a single error.
+func (param *Parameter) formatError(sb *strings.Builder, e *EvalError, args []string) {
+  sb.WriteString(e.Error())
+  sb.WriteString("\n")
+
+  if e.Position >= 0 && e.Position < len(args) {
+    quoted := quoteArgs(args)
+    col := 0
+    for _, arg := range quoted[:e.Position] {
+      col += utf8.RuneCountInString(arg) + 1
+    }
+    sb.WriteString("  " + strings.Join(quoted, " ") + "\n")
+    sb.WriteString("  " + strings.Repeat(" ", col) + "^")
+    sb.WriteString(strings.Repeat("~", utf8.RuneCountInString(quoted[e.Position]) - 1) + "\n")
+  }
+
+  if hint := param.getContext(args, e.Position).getHint(e); len(hint) > 0 {
+    sb.WriteString("  hint: ")
+    sb.WriteString(hint)
+    sb.WriteString("\n")
+  }
+}
+
+// Used internally. Returns the parameter definitions that apply to the command line argument at the specified
+// position, considering subcommands. A negative position considers all arguments.
+func (param *Parameter) getContext(args []string, pos int) *Parameter {
+  if pos < 0 || pos > len(args) { pos = len(args) }
+  for idx := 1; idx < pos; idx++ {
+    if isOption(args[idx]) {
+      // skipping option arguments
+      name, _, hasValue := strings.Cut(args[idx], "=")
+      if def, ok := param.aliases[getOptionName(name)]; ok {
+        numArgs := def.numArgs
+        if numArgs > 0 && hasValue { numArgs-- }
+        idx += numArgs
+      }
+      continue
+    }
+    c := param.getCommand(args[idx])
+    if c == nil { break }   // remaining entries are extra arguments
+    param = c.param
+  }
+  return param
+}
+
+// Used internally. Returns a hint for the given error, or an empty string if not available.
+func (param *Parameter) getHint(e *EvalError) string {
+  switch e.Kind {
+    case UnrecognizedOption:
+      names := make([]string, 0, len(param.aliases))
+      for name := range param.aliases {
+        names = append(names, name)
+      }
+      if s := getSuggestion(e.Value, names); len(s) > 0 {
+        return "did you mean \"" + getPrefixedName(s) + "\"?"
+      }
+    case MissingValue, InvalidValue, MissingArgument:
+      var vtype ValueType
+      var choices []string
+      if len(e.Positional) > 0 {
+        p := param.getPositional(e.Positional)
+        if p == nil { break }
+        vtype, choices = p.vtype, getValidatorChoices(p.validators)
+      } else {
+        def, ok := param.aliases[e.Option]
+        if !ok { break }
+        idx := e.Index
+        if idx >= 0 && idx < len(def.types) { vtype = def.types[idx] }
+        choices = def.getChoices(idx)
+      }
+      if len(choices) > 0 {
+        if e.Kind == InvalidValue {
+          if s := getSuggestion(e.Value, choices); len(s) > 0 { return "did you mean \"" + s + "\"?" }
+        }
+        return "expected one of " + quoteList(choices)
+      }
+      if vtype != TypeString { return "expected " + vtype.String() }
+    case UnexpectedArgument:
+      names := make([]string, 0, len(param.commands))
+      for _, c := range param.commands {
+        names = append(names, c.name)
+      }
+      if s := getSuggestion(e.Value, names); len(s) > 0 {
+        return "did you mean the command \"" + s + "\"?"
+      }
+  }
+  return ""
+}
+
+// Used internally. Returns the candidate that most likely was meant by the given misspelled string, or an empty string
+// if no candidate is similar enough.
+func getSuggestion(s string, candidates []string) string {
+  sorted := append([]string(nil), candidates...)
+  sort.Strings(sorted)
+
+  retVal := ""
+  best := utf8.RuneCountInString(s) / 3 + 1   // maximum accepted distance + 1
+  for _, c := range sorted {
+    if c == s { continue }
+    d := getDistance(strings.ToLower(s), strings.ToLower(c))
+    if len(s) >= 2 && strings.HasPrefix(c, s) { d = 1 }   // abbreviations are accepted as well
+    if d < best {
+      retVal, best = c, d
+    }
+  }
+  return retVal
+}
+
+// Used internally. Returns the Levenshtein distance between the given strings.
+func getDistance(a, b string) int {
+  ra, rb := []rune(a), []rune(b)
+  row := make([]int, len(rb) + 1)
+  for j := range row {
+    row[j] = j
+  }
+  for i := 1; i <= len(ra); i++ {
+    prev := row[0]
+    row[0] = i
+    for j := 1; j <= len(rb); j++ {
+      cur := row[j]
+      cost := 1
+      if ra[i - 1] == rb[j - 1] { cost = 0 }
+      row[j] = prev + cost
+      if row[j - 1] + 1 < row[j] { row[j] = row[j - 1] + 1 }
+      if cur + 1 < row[j] { row[j] = cur + 1 }
+      prev = cur
+    }
+  }
+  return row[len(rb)]
+}
+
+// Used internally. Returns the given arguments as they would be typed in a POSIX shell.
+func quoteArgs(args []string) []string {
+  retVal := make([]string, len(args))
+  for i, arg := range args {
+    retVal[i] = quoteArg(arg)
+  }
+  return retVal
+}
+
+// Used internally. Returns the given argument as it would be typed in a POSIX shell. The argument is only quoted if
+// it contains special characters.
+func quoteArg(arg string) string {
+  if len(arg) == 0 { return "''" }
+  for _, ch := range arg {
+    if !(ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9' ||
+         strings.ContainsRune("-_./=:,+@%", ch)) {
+      return shellQuote(arg)
+    }
+  }
+  return arg
+}
@@ -0,0 +1,26 @@
+package cmdargs
+
+import (
+  "testing"
+)
+
+func TestFormatErrorString(t *testing.T) {
+  param := Create()
+  param.AddParameter("verbose", []string{"v"}, 0)
+  line := `app  --verbos "my file"`
+  err := param.EvaluateString(line)
+  want := "Unrecognized option: \"--verbos\" or \"-verbos\"\n" +
+          "  app --verbos 'my file'\n" +
+          "      ^~~~~~~~\n" +
+          "  hint: did you mean \"--verbose\"?\n"
+  if s := param.FormatErrorString(err, line); s != want {
+    t.Errorf("FormatErrorString =\n%s\nwant:\n%s", s, want)
+  }
+  args, _ := SplitCommandLine(line)
+  if s := param.FormatError(err, args); s != want {
+    t.Errorf("FormatError =\n%s\nwant:\n%s", s, want)
+  }
+  if s := param.FormatErrorString(nil, line); s != "" {
+    t.Errorf("FormatErrorString(nil) = %q; want empty string", s)
+  }
+}
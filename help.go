@@ -0,0 +1,107 @@
+package cmdargs
+
+import (
+  "errors"
+  "fmt"
+  "io"
+  "os"
+  "runtime/debug"
+)
+
+// ErrHelp is returned by Parameter.Evaluate if a help option registered by AddHelpParameter was specified.
+var ErrHelp = errors.New("help requested")
+
+// ErrVersion is returned by Parameter.Evaluate if a version option registered by AddVersionParameter was specified.
+var ErrVersion = errors.New("version requested")
+
+// Special parameter types
+const (
+  paramRegular = iota
+  paramHelp
+  paramVersion
+)
+
+// AddHelpParameter adds a parameter definition that is handled by Evaluate automatically.
+//
+// If the option is specified at the command line, Evaluate writes the usage output generated by WriteUsage to the
+// output defined by SetOutput and returns ErrHelp. Validation functions registered by AddCheck are skipped, and
+// evaluation errors of other options are ignored.
+//
+// name and aliases are treated as in AddParameter.
+func (param *Parameter) AddHelpParameter(name string, aliases []string) {
+  param.addSpecialParameter(name, aliases, paramHelp, "Print this help and exit.")
+}
+
+// AddVersionParameter adds a parameter definition that is handled by Evaluate automatically.
+//
+// If the option is specified at the command line, Evaluate writes the application name and version to the output
+// defined by SetOutput and returns ErrVersion. Validation functions registered by AddCheck are skipped, and
+// evaluation errors of other options are ignored.
+//
+// name and aliases are treated as in AddParameter. If version is empty, the main module version of the build
+// information embedded in the binary is used.
+func (param *Parameter) AddVersionParameter(name string, aliases []string, version string) {
+  param.version = version
+  param.addSpecialParameter(name, aliases, paramVersion, "Print version information and exit.")
+}
+
+// SetOutput defines the destination for the output of automatically handled options. Specify nil to use the output
+// of the parent command for subcommands, or os.Stdout otherwise.
+func (param *Parameter) SetOutput(w io.Writer) {
+  param.out = w
+}
+
+// GetVersion returns the version string written by the version option.
+func (param *Parameter) GetVersion() string {
+  if len(param.version) > 0 { return param.version }
+  if info, ok := debug.ReadBuildInfo(); ok && len(info.Main.Version) > 0 {
+    return info.Main.Version
+  }
+  return "unknown"
+}
+
+
+// Used internally. Adds a parameter definition of given type without arguments.
+func (param *Parameter) addSpecialParameter(name string, aliases []string, kind int, desc string) {
+  param.AddParameter(name, aliases, 0)
+  if p, ok := param.aliases[getOptionName(name)]; ok {
+    p.kind = kind
+    if len(p.desc) == 0 {
+      p.desc = desc
+    }
+  }
+}
+
+// Used internally. Returns the output writer for automatically handled options.
+func (param *Parameter) getOutput() io.Writer {
+  if param.out != nil { return param.out }
+  if param.parent != nil { return param.parent.getOutput() }
+  return os.Stdout
+}
+
+// Used internally. Returns the first help or version parameter definition referenced by the given command line
+// arguments, or nil if not available. Arguments of subcommands are not considered.
+func (param *Parameter) findSpecial(args []string) *paramType {
+  for _, arg := range args {
+    if param.getCommand(arg) != nil { break }
+    if isOption(arg) {
+      if def, ok := param.aliases[getOptionName(arg)]; ok && def.kind != paramRegular {
+        return def
+      }
+    }
+  }
+  return nil
+}
+
+// Used internally. Writes the output of the given help or version parameter and returns the respective error.
+// r contains the arguments evaluated so far.
+func (param *Parameter) handleSpecial(def *paramType, r *Result) error {
+  w := param.getOutput()
+  prog := param.getProgramNameFor(r)
+  if def.kind == paramVersion {
+    if _, err := fmt.Fprintf(w, "%s %s\n", prog, param.GetVersion()); err != nil { return err }
+    return ErrVersion
+  }
+  if err := param.writeUsage(w, prog); err != nil { return err }
+  return ErrHelp
+}
@@ -0,0 +1,52 @@
+//go:build go1.23
+
+package cmdargs
+
+import (
+  "iter"
+)
+
+// All returns an iterator over the evaluated options in the order they were specified at the command line. Options
+// are provided as described for GetArgAt, except that the option arguments are not copied and must not be modified.
+func (r *Result) All() iter.Seq[Argument] {
+  return func(yield func(Argument) bool) {
+    for idx := range r.options {
+      if !yield(r.getArgument(idx)) { return }
+    }
+  }
+}
+
+// Occurrences returns an iterator over all instances of the option with the given name. Options are provided as
+// described for All.
+func (r *Result) Occurrences(name string) iter.Seq[Argument] {
+  return func(yield func(Argument) bool) {
+    for _, pos := range r.index[r.param.getLongOptionName(name)] {
+      if !yield(r.getArgument(pos)) { return }
+    }
+  }
+}
+
+// Extras returns an iterator over the indices and values of the extra arguments.
+func (r *Result) Extras() iter.Seq2[int, Generic] {
+  return func(yield func(int, Generic) bool) {
+    for idx, value := range r.extra {
+      if !yield(idx, value) { return }
+    }
+  }
+}
+
+// All returns an iterator over the options evaluated by a previous call to Evaluate. See Result.All for details.
+func (param *Parameter) All() iter.Seq[Argument] {
+  return param.getResult().All()
+}
+
+// Occurrences returns an iterator over all instances of the option with the given name evaluated by a previous call
+// to Evaluate. See Result.Occurrences for details.
+func (param *Parameter) Occurrences(name string) iter.Seq[Argument] {
+  return param.getResult().Occurrences(name)
+}
+
+// Extras returns an iterator over the extra arguments evaluated by a previous call to Evaluate.
+func (param *Parameter) Extras() iter.Seq2[int, Generic] {
+  return param.getResult().Extras()
+}
@@ -0,0 +1,177 @@
+package cmdargs
+
+import (
+  "fmt"
+  "io"
+  "strings"
+)
+
+// WriteManPage writes a manual page in roff format generated from the parameter definitions to w.
+//
+// section specifies the manual section, e.g. 1 for user commands. The page consists of the sections NAME,
+// SYNOPSIS, DESCRIPTION, OPTIONS, COMMANDS, ENVIRONMENT, EXAMPLES and FILES. Sections without content are
+// omitted.
+// The output depends only on the definitions, so that it can be compared against reference files. Use
+// SetProgramName to avoid a dependency on the name of the executable.
+func (param *Parameter) WriteManPage(w io.Writer, section int) error {
+  var sb strings.Builder
+  prog := param.getProgramName()
+
+  version := param.version
+  if len(version) > 0 { version = prog + " " + version }
+  fmt.Fprintf(&sb, ".TH %s %d \"\" %s\n", roffQuote(strings.ToUpper(prog)), section, roffQuote(version))
+
+  sb.WriteString(".SH NAME\n")
+  summary := strings.SplitN(strings.TrimSpace(param.progDesc), "\n", 2)[0]
+  if len(summary) > 0 {
+    sb.WriteString(roffEscape(prog) + " \\- " + roffEscape(summary) + "\n")
+  } else {
+    sb.WriteString(roffEscape(prog) + "\n")
+  }
+
+  sb.WriteString(".SH SYNOPSIS\n")
+  param.writeManSynopsis(&sb, prog)
+
+  if len(param.progDesc) > 0 {
+    sb.WriteString(".SH DESCRIPTION\n")
+    writeManText(&sb, param.progDesc)
+  }
+
+  if len(param.defs) > 0 {
+    sb.WriteString(".SH OPTIONS\n")
+    param.writeManOptions(&sb)
+  }
+
+  if len(param.commands) > 0 {
+    sb.WriteString(".SH COMMANDS\n")
+    for _, c := range param.commands {
+      sb.WriteString(".SS " + roffQuote(prog + " " + c.name) + "\n")
+      c.param.writeManSynopsis(&sb, prog + " " + c.name)
+      if len(c.param.progDesc) > 0 {
+        sb.WriteString(".PP\n")
+        writeManText(&sb, c.param.progDesc)
+      }
+      c.param.writeManOptions(&sb)
+    }
+  }
+
+  envDefs := param.getEnvDefs()
+  if len(envDefs) > 0 {
+    sb.WriteString(".SH ENVIRONMENT\n")
+    for _, def := range envDefs {
+      sb.WriteString(".TP\n.B " + roffEscape(def.env) + "\n")
+      sb.WriteString("Provides the value of " + roffOption(def.name) + " if the option is not specified.\n")
+    }
+  }
+
+  if len(param.examples) > 0 {
+    sb.WriteString(".SH EXAMPLES\n")
+    for _, e := range param.examples {
+      sb.WriteString(".PP\n")
+      writeManText(&sb, e.desc)
+      sb.WriteString(".RS\n.nf\n" + roffEscape(e.cmdLine) + "\n.fi\n.RE\n")
+    }
+  }
+
+  if len(param.files) > 0 {
+    sb.WriteString(".SH FILES\n")
+    for _, f := range param.files {
+      sb.WriteString(".TP\n.I " + roffEscape(f.path) + "\n")
+      writeManText(&sb, f.desc)
+    }
+  }
+
+  _, err := io.WriteString(w, sb.String())
+  return err
+}
+
+
+// Used internally. Writes the synopsis of the command.
+func (param *Parameter) writeManSynopsis(sb *strings.Builder, prog string) {
+  sb.WriteString(".B " + roffEscape(prog) + "\n")
+  for _, def := range param.defs {
+    sb.WriteString("[" + roffOption(def.name))
+    for _, m := range def.getMetavars() {
+      sb.WriteString(" \\fI" + roffEscape(m) + "\\fR")
+    }
+    sb.WriteString("]\n")
+  }
+  for _, p := range param.positionals {
+    if item := p.getSynopsis(); len(item) > 0 {
+      sb.WriteString("\\fI" + roffEscape(item) + "\\fR\n")
+    }
+  }
+  if len(param.commands) > 0 {
+    sb.WriteString("\\fICOMMAND\\fR [\\fIARGS\\fR...]\n")
+  }
+}
+
+// Used internally. Writes the option list of the command, grouped by sections.
+func (param *Parameter) writeManOptions(sb *strings.Builder) {
+  for _, group := range param.getGroups() {
+    if !param.hasGroup(group) { continue }
+    if len(group) > 0 {
+      sb.WriteString(".SS " + roffQuote(group) + "\n")
+    }
+    for _, def := range param.defs {
+      if def.group != group { continue }
+      names := make([]string, 0, len(def.aliases) + 1)
+      for _, a := range append([]string{def.name}, def.aliases...) {
+        names = append(names, roffOption(a))
+      }
+      sb.WriteString(".TP\n" + strings.Join(names, ", "))
+      for _, m := range def.getMetavars() {
+        sb.WriteString(" \\fI" + roffEscape(m) + "\\fR")
+      }
+      sb.WriteString("\n")
+      writeManText(sb, def.getUsageText())
+    }
+  }
+}
+
+// Used internally. Returns all parameter definitions of the command and its subcommands that are bound to
+// environment variables.
+func (param *Parameter) getEnvDefs() []*paramType {
+  retVal := make([]*paramType, 0)
+  for _, def := range param.defs {
+    if len(def.env) > 0 {
+      retVal = append(retVal, def)
+    }
+  }
+  for _, c := range param.commands {
+    retVal = append(retVal, c.param.getEnvDefs()...)
+  }
+  return retVal
+}
+
+// Used internally. Writes the given text as paragraphs.
+func writeManText(sb *strings.Builder, text string) {
+  for i, line := range strings.Split(strings.TrimSpace(text), "\n") {
+    line = strings.TrimSpace(line)
+    if len(line) == 0 {
+      if i > 0 { sb.WriteString(".PP\n") }
+      continue
+    }
+    sb.WriteString(roffEscape(line) + "\n")
+  }
+}
+
+// Used internally. Returns the given normalized option name in bold with prefix.
+func roffOption(name string) string {
+  return "\\fB" + roffEscape(getPrefixedName(name)) + "\\fR"
+}
+
+// Used internally. Returns the given string as a double-quoted macro argument.
+func roffQuote(s string) string {
+  return "\"" + strings.ReplaceAll(roffEscape(s), "\"", "\\(dq") + "\""
+}
+
+// Used internally. Escapes characters with special meaning in roff.
+func roffEscape(s string) string {
+  s = strings.ReplaceAll(s, "\\", "\\e")
+  s = strings.ReplaceAll(s, "-", "\\-")
+  if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+    s = "\\&" + s
+  }
+  return s
+}
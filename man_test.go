@@ -0,0 +1,82 @@
+package cmdargs
+
+import (
+  "flag"
+  "os"
+  "path/filepath"
+  "strings"
+  "testing"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata")
+
+// Used internally. Returns parameter definitions that make use of every feature of the generated documentation.
+func newDocParam() *Parameter {
+  param := Create()
+  param.SetProgramName("tool")
+  param.SetProgramDescription("Builds and inspects projects.\n\n.toolrc files in the current directory are read first.")
+  param.AddVersionParameter("version", []string{"V"}, "1.2.3")
+  param.AddHelpParameter("help", []string{"h"})
+  param.AddParameter("build", nil, 0)
+  param.SetParameterDescription("build", "Build the project before running the command.")
+  param.AddParameter("jobs", []string{"j"}, 1)
+  param.SetParameterTypes("jobs", TypeInt)
+  param.SetParameterMetavars("jobs", "N")
+  param.SetParameterDefault("jobs", "4")
+  param.SetParameterEnv("jobs", "TOOL_JOBS")
+  param.SetParameterDescription("jobs", "Number of parallel jobs.")
+  param.AddParameter("color", nil, 1)
+  param.AddValidator("color", Choices("auto", "always", "never"))
+  param.SetParameterGroup("color", "Output")
+  param.SetParameterDescription("color", "Colorize the output.")
+  param.AddParameter("quiet", []string{"q"}, 0)
+  param.SetParameterEnv("quiet", "TOOL_QUIET")
+  param.SetParameterGroup("quiet", "Output")
+  param.SetParameterDescription("quiet", "Suppress messages, e.g. \\-warnings.")
+
+  build := Create()
+  build.SetProgramDescription("Builds the project.")
+  build.AddParameter("force", []string{"f"}, 0)
+  build.SetParameterDescription("force", "Rebuild all targets.")
+  build.AddParameter("target", nil, 1)
+  build.SetParameterMetavars("target", "NAME")
+  build.SetParameterDescription("target", "Build only the given target.")
+  param.AddCommand("build", build)
+
+  param.AddExample("tool -j 8 build --force", "Rebuild everything with 8 jobs.")
+  param.AddFile("~/.toolrc", "User configuration.")
+  return param
+}
+
+// Used internally. Compares got with the content of the given file in testdata. The file is updated instead if the
+// -update flag is specified.
+func checkGolden(t *testing.T, name string, got string) {
+  t.Helper()
+  path := filepath.Join("testdata", name)
+  if *update {
+    if err := os.WriteFile(path, []byte(got), 0644); err != nil { t.Fatal(err) }
+    return
+  }
+  want, err := os.ReadFile(path)
+  if err != nil { t.Fatalf("%v (run \"go test -update\" to create it)", err) }
+  if got != string(want) {
+    t.Errorf("output differs from %s:\n--- got\n%s--- want\n%s", path, got, want)
+  }
+}
+
+func TestWriteManPage(t *testing.T) {
+  var sb strings.Builder
+  if err := newDocParam().WriteManPage(&sb, 1); err != nil {
+    t.Fatalf("WriteManPage: %v", err)
+  }
+  checkGolden(t, "tool.1", sb.String())
+
+  // output must not depend on anything but the definitions
+  var sb2 strings.Builder
+  param := newDocParam()
+  if err := param.Evaluate([]string{"other", "--build", "build", "-f"}); err != nil { t.Fatalf("Evaluate: %v", err) }
+  param.WriteManPage(&sb2, 1)
+  if sb2.String() != sb.String() {
+    t.Error("WriteManPage output depends on evaluated arguments")
+  }
+}
@@ -0,0 +1,228 @@
+package cmdargs
+
+import (
+  "fmt"
+  "strings"
+)
+
+// Definition for a named positional argument
+type positionalType struct {
+  name        string          // Name of the positional argument
+  minArgs     int             // Minimum number of values
+  maxArgs     int             // Maximum number of values, or -1 for no limit
+  vtype       ValueType       // Expected type of the values
+  validators  []Validator     // Validators for the values
+  desc        string          // Description of the positional argument
+  hint        CompletionHint  // Completion hint for the values
+}
+
+// AddPositional adds or updates the named positional argument specified by "name".
+//
+// Positional arguments are assigned to the extra arguments in the order they were added. minArgs and maxArgs specify
+// the number of values belonging to the positional argument. Specify a negative maxArgs to accept any number of
+// values. Extra arguments are assigned as early as possible, while leaving enough arguments for the minimum
+// requirements of subsequent positional arguments.
+//
+// If positional arguments are defined, Evaluate rejects extra arguments that don't fit into the definitions.
+// Extra arguments remain available by GetArgExtra.
+func (param *Parameter) AddPositional(name string, minArgs, maxArgs int) {
+  if len(name) == 0 { return }
+  if minArgs < 0 { minArgs = 0 }
+  if maxArgs >= 0 && maxArgs < minArgs { maxArgs = minArgs }
+
+  p := param.getPositional(name)
+  if p == nil {
+    p = &positionalType{name: name}
+    param.positionals = append(param.positionals, p)
+  }
+  p.minArgs = minArgs
+  p.maxArgs = maxArgs
+}
+
+// RemovePositional removes the positional argument of given name. Returns whether there was a definition that could
+// be removed.
+func (param *Parameter) RemovePositional(name string) bool {
+  for i, p := range param.positionals {
+    if p.name == name {
+      param.positionals = append(param.positionals[:i], param.positionals[i+1:]...)
+      return true
+    }
+  }
+  return false
+}
+
+// SetPositionalType declares the expected type of the values of the positional argument specified by "name".
+// Values are converted and checked as described for SetParameterTypes.
+//
+// Returns whether a positional argument of given name exists.
+func (param *Parameter) SetPositionalType(name string, vtype ValueType) bool {
+  p := param.getPositional(name)
+  if p != nil {
+    p.vtype = vtype
+  }
+  return p != nil
+}
+
+// AddPositionalValidator attaches validators to the values of the positional argument specified by "name". Nil
+// validators are ignored.
+//
+// Returns whether a positional argument of given name exists.
+func (param *Parameter) AddPositionalValidator(name string, v ...Validator) bool {
+  p := param.getPositional(name)
+  if p != nil {
+    for _, validator := range v {
+      if validator != nil {
+        p.validators = append(p.validators, validator)
+      }
+    }
+  }
+  return p != nil
+}
+
+// SetPositionalDescription defines the description of the positional argument specified by "name" for the usage
+// output.
+//
+// Returns whether a positional argument of given name exists.
+func (param *Parameter) SetPositionalDescription(name string, desc string) bool {
+  p := param.getPositional(name)
+  if p != nil {
+    p.desc = desc
+  }
+  return p != nil
+}
+
+// SetPositionalCompletion defines how shell completion scripts complete the values of the positional argument
+// specified by "name".
+//
+// Returns whether a positional argument of given name exists.
+func (param *Parameter) SetPositionalCompletion(name string, hint CompletionHint) bool {
+  p := param.getPositional(name)
+  if p != nil {
+    p.hint = hint
+  }
+  return p != nil
+}
+
+// GetPositional returns the values assigned to the positional argument of given name by a previous call to
+// Evaluate. Returns an empty list if the positional argument doesn't exist.
+func (param *Parameter) GetPositional(name string) GenericList {
+  return param.getResult().GetPositional(name)
+}
+
+
+// Used internally. Returns the positional argument of given name, or nil if not available.
+func (param *Parameter) getPositional(name string) *positionalType {
+  for _, p := range param.positionals {
+    if p.name == name { return p }
+  }
+  return nil
+}
+
+// Used internally. Returns the positional argument that receives the extra argument at the specified index, assuming
+// that preceding positional arguments receive their maximum number of values. Returns nil if not available.
+func (param *Parameter) getPositionalAt(index int) *positionalType {
+  for _, p := range param.positionals {
+    if p.maxArgs < 0 || index < p.maxArgs { return p }
+    index -= p.maxArgs
+  }
+  return nil
+}
+
+// Used internally. Assigns the extra arguments to the positional arguments. offset indicates the index of the first
+// extra argument in the command line arguments list.
+func (r *Result) evalPositionals(offset int) error {
+  r.posArgs = make(map[string]GenericList, len(r.param.positionals))
+  required := 0
+  for _, p := range r.param.positionals {
+    required += p.minArgs
+  }
+
+  idx := 0
+  for _, p := range r.param.positionals {
+    required -= p.minArgs
+    n := len(r.extra) - idx - required
+    if p.maxArgs >= 0 && n > p.maxArgs { n = p.maxArgs }
+    if n < p.minArgs {
+      // subsequent positional arguments report missing values if possible
+      if available := len(r.extra) - idx; available < p.minArgs {
+        return &EvalError{Kind: MissingArgument, Positional: p.name, Index: -1, Position: -1, Value: p.getMetavar(),
+                          Err: fmt.Errorf("available=%d, need=%d", available, p.minArgs)}
+      }
+      n = p.minArgs
+    }
+
+    values := make(GenericList, 0, n)
+    for ; n > 0; n, idx = n-1, idx+1 {
+      v, err := p.convert(r.extra[idx], offset + idx)
+      if err != nil { return err }
+      r.extra[idx] = v
+      values = append(values, v)
+    }
+    r.posArgs[p.name] = values
+  }
+
+  if idx < len(r.extra) {
+    return &EvalError{Kind: UnexpectedArgument, Index: -1, Position: offset + idx, Value: r.extra[idx].ToString()}
+  }
+  return nil
+}
+
+// Used internally. Converts the given value to the declared type and applies validators.
+// pos indicates the index of the value in the command line arguments list.
+func (p *positionalType) convert(value Generic, pos int) (Generic, error) {
+  v, ok := p.vtype.convert(value)
+  if !ok {
+    return nil, &EvalError{Kind: InvalidValue, Positional: p.name, Index: -1, Position: pos, Value: value.ToString(),
+                           Err: fmt.Errorf("expected %s", p.vtype)}
+  }
+  for _, validator := range p.validators {
+    if err := validator.Validate(v); err != nil {
+      return nil, &EvalError{Kind: InvalidValue, Positional: p.name, Index: -1, Position: pos, Value: v.ToString(),
+                             Err: err}
+    }
+  }
+  return v, nil
+}
+
+// Used internally. Returns the placeholder name of the positional argument.
+func (p *positionalType) getMetavar() string {
+  return strings.ToUpper(strings.ReplaceAll(p.name, "-", "_"))
+}
+
+// Used internally. Returns the synopsis of the positional argument, e.g. "FILES..." or "[NAME]".
+func (p *positionalType) getSynopsis() string {
+  metavar := p.getMetavar()
+  items := make([]string, 0, p.minArgs + 1)
+  for i := 0; i < p.minArgs; i++ {
+    items = append(items, metavar)
+  }
+  switch {
+    case p.maxArgs < 0 && p.minArgs > 0:
+      items[len(items) - 1] += "..."
+    case p.maxArgs < 0 || p.maxArgs - p.minArgs > 1:
+      items = append(items, "[" + metavar + "...]")
+    case p.maxArgs > p.minArgs:
+      items = append(items, "[" + metavar + "]")
+  }
+  return strings.Join(items, " ")
+}
+
+// Used internally. Returns the description of the positional argument, including choices.
+func (p *positionalType) getUsageText() string {
+  s := p.desc
+  if choices := getValidatorChoices(p.validators); len(choices) > 0 {
+    s += " (choices: " + strings.Join(choices, ", ") + ")"
+  }
+  return strings.TrimSpace(s)
+}
+
+// Used internally. Returns the allowed choices and the completion hint for the values of the positional argument.
+func (p *positionalType) getCompletion() (choices []string, hint CompletionHint) {
+  return getValueCompletion(p.hint, p.validators, p.vtype)
+}
+
+// Used internally. Returns completion candidates for a value of the positional argument.
+func (p *positionalType) complete(prefix string) ([]Completion, CompletionHint) {
+  choices, hint := p.getCompletion()
+  return completeChoices(choices, hint, prefix)
+}
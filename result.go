@@ -0,0 +1,354 @@
+package cmdargs
+
+import (
+  "errors"
+  "path/filepath"
+  "sort"
+)
+
+// Result contains the command line arguments evaluated by Parameter.Parse. It is not modified after Parse returns
+// and can be accessed concurrently.
+//
+// Option names are resolved by the parameter definitions used for evaluation.
+type Result struct {
+  param     *Parameter      // Parameter definitions used for evaluation
+  options   optionList      // Options are listed sequentially by their appearance in the command line arguments list
+  index     map[string][]int  // Positions in the options list, indexed by normalized long option name
+  extra     GenericList     // Remaining list of unparsed command line arguments (e.g. file names, etc.)
+  self      string          // Contains the application name (args[0]), unless it is identified as an option.
+  command   *commandType    // Evaluated subcommand, if available
+  sub       *Result         // Result of the evaluated subcommand, if available
+  parent    *Result         // Result of the parent command, if this is the result of a subcommand
+  posArgs   map[string]GenericList  // Values of the named positional arguments
+  extraPos  int             // Position of the first extra argument in the command line arguments list
+  rawExtra  []string        // Extra arguments as specified at the command line
+
+  optStore  []optionType    // Preallocated storage for options
+  valStore  GenericList     // Preallocated storage for option arguments and extra arguments
+}
+
+
+// GetArgSelf returns the first argument of the argument list, unless it was identified as a regular option.
+// It is usually the application name.
+func (r *Result) GetArgSelf() string {
+  return r.self
+}
+
+
+
+// GetArgExtraLength returns the number of available extra arguments that were not evaluated as regular options.
+func (r *Result) GetArgExtraLength() int {
+  return len(r.extra)
+}
+
+// GetArgExtra returns the extra argument at the specified index.
+func (r *Result) GetArgExtra(index int) Generic {
+  if index < 0 || index > r.GetArgExtraLength() { return String("") }
+  return r.extra[index]
+}
+
+// GetArgExtraPosition returns the index of the extra argument at the specified index in the command line arguments
+// list. Returns -1 if the extra argument doesn't exist.
+func (r *Result) GetArgExtraPosition(index int) int {
+  if index < 0 || index >= len(r.rawExtra) { return -1 }
+  return r.extraPos + index
+}
+
+// GetArgExtraToken returns the extra argument at the specified index as specified at the command line, i.e. before
+// conversion to the type of a positional argument. Returns an empty string if the extra argument doesn't exist.
+func (r *Result) GetArgExtraToken(index int) string {
+  if index < 0 || index >= len(r.rawExtra) { return "" }
+  return r.rawExtra[index]
+}
+
+// GetExpandedArgExtra treats the given argument as a wildcard and expands it relative to current directory.
+//
+// Returns a list with all matching path strings (which may be empty if no match is found), or an empty list on error.
+func (r *Result) GetExpandedArgExtra(index int) []string {
+  retVal := make([]string, 0)
+  if index < 0 || index > r.GetArgExtraLength() { return retVal }
+
+  expanded, err := filepath.Glob(r.extra[index].ToString())
+  if err == nil {
+    retVal = append(retVal, expanded...)
+  }
+  return retVal
+}
+
+
+
+// GetArgLength returns the number of evaluated options.
+func (r *Result) GetArgLength() int {
+  return len(r.options)
+}
+
+// GetArgExists returns whether the argument of given name has been evaluated. It considers option names and
+// aliases.
+func (r *Result) GetArgExists(name string) bool {
+  return len(r.index[r.param.getLongOptionName(name)]) > 0
+}
+
+// GetArgCount returns the number of instances of the option with the given name.
+func (r *Result) GetArgCount(name string) int {
+  return len(r.index[r.param.getLongOptionName(name)])
+}
+
+// GetArgIndex returns the index of the specified option in the command line options list.
+//
+// name specifies the option name or alias.
+// startIndex indicates the position where to start the search. Positive indices are relative to the first option in
+// the list. Specify a negative index to search backwards. Negative indices are relative to the position directly
+// behind the last option in the list.
+//
+// Returns a positive index when searching forward and a negative index when searching backwards. Both index variants
+// can be fed directly to the GetArgAt function to return the Argument structure of the given option.
+func (r *Result) GetArgIndex(name string, startIndex int) (pos int, exists bool) {
+  pos = startIndex
+  positions := r.index[r.param.getLongOptionName(name)]
+  if len(positions) == 0 { return }
+
+  if startIndex < 0 {
+    // searching backwards: last position not greater than startIndex
+    startIndex = len(r.options) + startIndex
+    if startIndex < 0 { return }
+    if i := sort.SearchInts(positions, startIndex + 1) - 1; i >= 0 {
+      pos, exists = positions[i] - len(r.options), true
+    }
+  } else {
+    // searching forward: first position not less than startIndex
+    if i := sort.SearchInts(positions, startIndex); i < len(positions) {
+      pos, exists = positions[i], true
+    }
+  }
+
+  return
+}
+
+// GetArgAt returns the option at the specified index as an Argument structure.
+//
+// If given index is positive, the function returns the argument relative to the first argument in the list.
+// If given index is negative, the function returns the argument relative to the position directly behind the
+// last option in the list.
+//
+// The second return value indicates success of the operation.
+func (r *Result) GetArgAt(index int) (arg Argument, err error) {
+  if index < 0 {
+    index = len(r.options) + index
+  }
+
+  if index < 0 || index >= len(r.options) {
+    err = errors.New("Parameter.GetArgNameByPosition: Invalid position")
+    return
+  }
+
+  arg = r.getArgument(index)
+  arg.Arguments = append(make(GenericList, 0, len(arg.Arguments)), arg.Arguments...)
+
+  return
+}
+
+// GetFirstArgOf returns the first instance of the option with the given name.
+func (r *Result) GetFirstArgOf(name string) (arg Argument, exists bool) {
+  var idx int
+  idx, exists = r.GetArgIndex(name, 0)
+  if exists {
+    var err error
+    arg, err = r.GetArgAt(idx)
+    exists = (err == nil)
+  }
+  return
+}
+
+// GetLastArgOf returns the last instance of the option with the given name.
+func (r *Result) GetLastArgOf(name string) (arg Argument, exists bool) {
+  var idx int
+  idx, exists = r.GetArgIndex(name, -1)
+  if exists {
+    var err error
+    arg, err = r.GetArgAt(idx)
+    exists = (err == nil)
+  }
+  return
+}
+
+// GetArgParam returns the option argument at the specified index of the last instance of the option with the
+// given name.
+//
+// The second return value indicates whether the option and the requested argument exist.
+func (r *Result) GetArgParam(name string, index int) (value Generic, exists bool) {
+  idx, found := r.GetArgIndex(name, -1)
+  if found {
+    value, exists = r.options[len(r.options) + idx].getValue(index)
+  }
+  return
+}
+
+// GetFirstArgParam returns the option argument at the specified index of the first instance of the option with the
+// given name.
+//
+// The second return value indicates whether the option and the requested argument exist.
+func (r *Result) GetFirstArgParam(name string, index int) (value Generic, exists bool) {
+  idx, found := r.GetArgIndex(name, 0)
+  if found {
+    value, exists = r.options[idx].getValue(index)
+  }
+  return
+}
+
+// GetArgSlot returns the option argument with the given placeholder name of the last instance of the option with
+// the given name. Placeholder names are defined by Parameter.SetParameterMetavars and are compared case-insensitively.
+//
+// The second return value indicates whether the option and the requested argument exist.
+func (r *Result) GetArgSlot(name string, slot string) (value Generic, exists bool) {
+  if arg, found := r.GetLastArgOf(name); found {
+    value, exists = arg.Value(slot)
+  }
+  return
+}
+
+// GetArgParamDefault behaves just like GetArgParam, but returns def if the option or the requested argument does
+// not exist.
+func (r *Result) GetArgParamDefault(name string, index int, def Generic) Generic {
+  if value, exists := r.GetArgParam(name, index); exists {
+    return value
+  }
+  return def
+}
+
+// GetArgParams returns the arguments of all instances of the option with the given name in the order they were
+// specified at the command line. Returns an empty list if the option doesn't exist.
+func (r *Result) GetArgParams(name string) GenericList {
+  retVal := make(GenericList, 0)
+  for _, pos := range r.index[r.param.getLongOptionName(name)] {
+    retVal = append(retVal, r.options[pos].value...)
+  }
+  return retVal
+}
+
+// GetArgValues returns the option arguments of the specified instance of the option with the given name without
+// copying them. Positive instance indices are relative to the first instance, negative indices are relative to the
+// position directly behind the last instance, i.e. -1 returns the last instance. The returned list must not be
+// modified.
+//
+// The second return value indicates whether the requested instance exists.
+func (r *Result) GetArgValues(name string, instance int) (values GenericList, exists bool) {
+  positions := r.index[r.param.getLongOptionName(name)]
+  if instance < 0 {
+    instance = len(positions) + instance
+  }
+  if instance >= 0 && instance < len(positions) {
+    values, exists = r.options[positions[instance]].value, true
+  }
+  return
+}
+
+// GetPositional returns the values assigned to the positional argument of given name. Returns an empty list if the
+// positional argument doesn't exist.
+func (r *Result) GetPositional(name string) GenericList {
+  retVal := make(GenericList, 0)
+  if values, ok := r.posArgs[name]; ok {
+    retVal = append(retVal, values...)
+  }
+  return retVal
+}
+
+// GetCommand returns the name and the evaluated arguments of the subcommand. Returns an empty name and nil if no
+// subcommand was specified.
+func (r *Result) GetCommand() (name string, sub *Result) {
+  if r.command != nil {
+    name, sub = r.command.name, r.sub
+  }
+  return
+}
+
+
+// Visit invokes the given functions for the evaluated options and extra arguments in the order they were specified
+// at the command line. Options bound to environment variables are visited after the options that were specified at
+// the command line. option receives the options as described for GetArgAt, except that the option arguments are not
+// copied and must not be modified. extra receives the extra arguments together with the name of the positional
+// argument they are assigned to, or an empty name if they are not assigned. Either function may be nil.
+//
+// Visiting stops as soon as a function returns false.
+func (r *Result) Visit(option func(arg Argument) bool, extra func(name string, value Generic) bool) {
+  if option != nil {
+    for idx := range r.options {
+      if !option(r.getArgument(idx)) { return }
+    }
+  }
+  if extra != nil {
+    idx := 0
+    for _, p := range r.param.positionals {
+      for _, value := range r.posArgs[p.name] {
+        if !extra(p.name, value) { return }
+        idx++
+      }
+    }
+    for ; idx < len(r.extra); idx++ {
+      if !extra("", r.extra[idx]) { return }
+    }
+  }
+}
+
+// Used internally. Returns the option at the specified index as an Argument structure. Option arguments are not
+// copied.
+func (r *Result) getArgument(index int) Argument {
+  option := r.options[index]
+  arg := Argument{Index: index, Name: option.name, Arguments: option.value, Alias: option.alias, Position: option.pos,
+                  Attached: option.attached, raw: option.raw}
+  if len(option.raw) > 0 {
+    arg.Token = option.raw[0]
+  }
+  if def, ok := r.param.aliases[option.name]; ok {
+    arg.slots = def.getMetavars()
+  }
+  return arg
+}
+
+// Used internally. Creates an empty Result for the given parameter definitions.
+func newResult(param *Parameter) *Result {
+  return &Result{param: param, options: make(optionList, 0), index: make(map[string][]int),
+                 extra: make(GenericList, 0)}
+}
+
+// Used internally. Preallocates storage for the evaluation of the given command line arguments. Every argument
+// provides at most one option, option argument or extra argument.
+func (r *Result) reserve(args []string) {
+  numOptions := 0
+  for _, arg := range args {
+    if isOption(arg) { numOptions++ }
+  }
+  r.options = make(optionList, 0, numOptions)
+  r.optStore = make([]optionType, 0, numOptions)
+  r.valStore = make(GenericList, 0, len(args))
+}
+
+// Used internally. Returns a new option of given name with room for the specified number of option arguments.
+// Storage is taken from the preallocated storage if possible.
+func (r *Result) newOption(name string, numArgs int) *optionType {
+  if len(r.optStore) == cap(r.optStore) {
+    r.optStore = make([]optionType, 0, 8)
+  }
+  r.optStore = append(r.optStore, optionType{name: name})
+  option := &r.optStore[len(r.optStore) - 1]
+  option.value = r.allocValues(numArgs)
+  return option
+}
+
+// Used internally. Returns an empty list with the specified capacity. Storage is taken from the preallocated storage
+// if possible.
+func (r *Result) allocValues(size int) GenericList {
+  n := len(r.valStore)
+  if n + size > cap(r.valStore) {
+    return make(GenericList, 0, size)
+  }
+  r.valStore = r.valStore[:n + size]
+  return r.valStore[n:n:n + size]
+}
+
+// Used internally. Appends the given option to the options list and returns its position.
+func (r *Result) addOption(option *optionType) int {
+  pos := len(r.options)
+  r.options = append(r.options, option)
+  r.index[option.name] = append(r.index[option.name], pos)
+  return pos
+}
@@ -0,0 +1,207 @@
+package cmdargs
+
+import (
+  "fmt"
+  "reflect"
+  "strings"
+  "sync"
+  "testing"
+)
+
+// Results must not be affected if the caller reuses the command line arguments list.
+func TestResultKeepsTokens(t *testing.T) {
+  param := Create()
+  param.AddParameter("size", nil, 2)
+  sub := Create()
+  sub.AddParameter("force", []string{"f"}, 0)
+  param.AddCommand("build", sub)
+
+  args := []string{"app", "--size=1", "2", "file"}
+  r, err := param.Parse(args)
+  if err != nil { t.Fatalf("Parse: %v", err) }
+  args2 := []string{"app", "--size", "3", "4", "build", "-f", "x"}
+  r2, err := param.Parse(args2)
+  if err != nil { t.Fatalf("Parse: %v", err) }
+  for i := range args { args[i] = "changed" }
+  for i := range args2 { args2[i] = "changed" }
+
+  arg, _ := r.GetArgAt(0)
+  if v, _ := arg.RawValue(1); arg.Token != "--size=1" || v != "2" {
+    t.Errorf("Token, RawValue(1) = %q, %q; want \"--size=1\", \"2\"", arg.Token, v)
+  }
+  if s := r.GetArgExtraToken(0); s != "file" {
+    t.Errorf("GetArgExtraToken(0) = %q; want \"file\"", s)
+  }
+  _, rsub := r2.GetCommand()
+  arg, _ = rsub.GetArgAt(0)
+  if arg.Token != "-f" || rsub.GetArgExtraToken(0) != "x" || rsub.GetArgSelf() != "build" {
+    t.Errorf("subcommand Token, GetArgExtraToken(0) = %q, %q; want \"-f\", \"x\"", arg.Token,
+             rsub.GetArgExtraToken(0))
+  }
+}
+
+// Used internally. Returns parameter definitions with a subcommand, and command lines that exercise them.
+func newResultParam() (*Parameter, [][]string) {
+  param := Create()
+  param.AddParameter("define", []string{"D"}, 1)
+  param.AddParameter("size", nil, 2)
+  param.SetParameterTypes("size", TypeInt, TypeInt)
+  param.SetParameterMetavars("size", "W", "H")
+  param.AddParameter("verbose", []string{"v"}, 0)
+  build := Create()
+  build.AddParameter("force", []string{"f"}, 0)
+  build.AddPositional("dir", 0, -1)
+  param.AddCommand("build", build)
+  return param, [][]string{
+    {"app", "-D", "a=1", "--size=640", "480", "-v", "file1", "file2"},
+    {"app", "-v", "--define", "b", "-D=c", "build", "-f", "src", "doc"},
+    {"other", "-v", "-v"},
+    {"-v", "x"},
+  }
+}
+
+// Evaluate must store the same results as returned by Parse, which are available by the argument functions of the
+// Parameter structure.
+func TestEvaluateMatchesParse(t *testing.T) {
+  param, cmdLines := newResultParam()
+  for _, args := range cmdLines {
+    r, err := param.Parse(args)
+    if err != nil { t.Fatalf("Parse(%q): %v", args, err) }
+    if err := param.Evaluate(args); err != nil { t.Fatalf("Evaluate(%q): %v", args, err) }
+    compareResult(t, args, param, r)
+    if name, sub := r.GetCommand(); len(name) > 0 {
+      _, cmd := param.GetCommand()
+      compareResult(t, args, cmd, sub)
+    }
+  }
+
+  // results of the second command line, as evaluated before Result was introduced
+  param.Evaluate(cmdLines[1])
+  if param.GetArgSelf() != "app" || param.GetArgLength() != 3 || param.GetArgExtraLength() != 0 {
+    t.Errorf("GetArgSelf, GetArgLength, GetArgExtraLength = %q, %d, %d; want app, 3, 0", param.GetArgSelf(),
+             param.GetArgLength(), param.GetArgExtraLength())
+  }
+  if s := fmt.Sprint(param.GetArgParams("D")); s != "[b c]" {
+    t.Errorf("GetArgParams(\"D\") = %s; want [b c]", s)
+  }
+  if pos, ok := param.GetArgIndex("v", -1); !ok || pos != -3 {
+    t.Errorf("GetArgIndex(\"v\", -1) = %d, %v; want -3, true", pos, ok)
+  }
+  _, build := param.GetCommand()
+  if build.GetArgSelf() != "build" || !build.GetArgExists("force") || build.GetArgExtraLength() != 2 ||
+     fmt.Sprint(build.GetPositional("dir")) != "[src doc]" {
+    t.Errorf("subcommand results differ")
+  }
+}
+
+// Used internally. Reports differences between the results available by the Parameter structure and r.
+func compareResult(t *testing.T, args []string, param *Parameter, r *Result) {
+  t.Helper()
+  if param.GetArgSelf() != r.GetArgSelf() || param.GetArgLength() != r.GetArgLength() ||
+     param.GetArgExtraLength() != r.GetArgExtraLength() {
+    t.Errorf("%q: GetArgSelf, GetArgLength or GetArgExtraLength differ", args)
+  }
+  for i := -r.GetArgLength(); i < r.GetArgLength(); i++ {
+    a1, err1 := param.GetArgAt(i)
+    a2, err2 := r.GetArgAt(i)
+    if !reflect.DeepEqual(a1, a2) || (err1 == nil) != (err2 == nil) {
+      t.Errorf("%q: GetArgAt(%d) = %+v; want %+v", args, i, a1, a2)
+    }
+  }
+  for i := 0; i < r.GetArgExtraLength(); i++ {
+    if param.GetArgExtra(i) != r.GetArgExtra(i) || param.GetArgExtraPosition(i) != r.GetArgExtraPosition(i) ||
+       param.GetArgExtraToken(i) != r.GetArgExtraToken(i) {
+      t.Errorf("%q: extra argument %d differs", args, i)
+    }
+  }
+  for _, name := range []string{"D", "size", "v", "f", "unknown"} {
+    if param.GetArgCount(name) != r.GetArgCount(name) ||
+       fmt.Sprint(param.GetArgParams(name)) != fmt.Sprint(r.GetArgParams(name)) {
+      t.Errorf("%q: GetArgCount or GetArgParams(%q) differ", args, name)
+    }
+    v1, ok1 := param.GetArgSlot(name, "H")
+    v2, ok2 := r.GetArgSlot(name, "H")
+    if v1 != v2 || ok1 != ok2 {
+      t.Errorf("%q: GetArgSlot(%q, \"H\") = %v; want %v", args, name, v1, v2)
+    }
+  }
+}
+
+// Parse must not modify the Parameter structure.
+func TestParseKeepsParameter(t *testing.T) {
+  param, cmdLines := newResultParam()
+  if err := param.Evaluate(cmdLines[1]); err != nil { t.Fatalf("Evaluate: %v", err) }
+  _, build := param.GetCommand()
+  stored, storedSub := param.result, build.result
+  defs := describeDefs(param) + describeDefs(build)
+
+  for _, args := range cmdLines {
+    if _, err := param.Parse(args); err != nil { t.Fatalf("Parse(%q): %v", args, err) }
+  }
+  param.Parse([]string{"app", "--unknown"})
+  param.Parse([]string{"app", "build", "--size"})
+
+  if param.result != stored || build.result != storedSub {
+    t.Error("Parse replaced the stored results")
+  }
+  if param.GetArgLength() != 3 || build.GetArgExtraLength() != 2 {
+    t.Error("Parse modified the stored results")
+  }
+  if s := describeDefs(param) + describeDefs(build); s != defs {
+    t.Errorf("Parse modified the definitions:\n%s\nwant:\n%s", s, defs)
+  }
+}
+
+// Concurrent calls of Parse must not interfere with each other. Run with -race.
+func TestParseConcurrent(t *testing.T) {
+  param, cmdLines := newResultParam()
+  want := make([]string, len(cmdLines))
+  for i, args := range cmdLines {
+    r, err := param.Parse(args)
+    if err != nil { t.Fatalf("Parse(%q): %v", args, err) }
+    want[i] = describeResult(r)
+  }
+
+  var wg sync.WaitGroup
+  for g := 0; g < 8; g++ {
+    wg.Add(1)
+    go func(g int) {
+      defer wg.Done()
+      for i := 0; i < 200; i++ {
+        idx := (g + i) % len(cmdLines)
+        r, err := param.Parse(cmdLines[idx])
+        if err != nil { t.Errorf("Parse(%q): %v", cmdLines[idx], err); return }
+        if s := describeResult(r); s != want[idx] {
+          t.Errorf("Parse(%q) = %s; want %s", cmdLines[idx], s, want[idx])
+          return
+        }
+      }
+    }(g)
+  }
+  wg.Wait()
+}
+
+// Used internally. Returns a textual representation of r and the results of its subcommands.
+func describeResult(r *Result) string {
+  var sb strings.Builder
+  for ; r != nil; _, r = r.GetCommand() {
+    sb.WriteString(r.GetArgSelf() + ":")
+    for arg := range r.All() {
+      fmt.Fprintf(&sb, " %s@%d%v", arg.Name, arg.Position, arg.Arguments)
+    }
+    for i, v := range r.Extras() {
+      fmt.Fprintf(&sb, " [%d]%v", i, v)
+    }
+    sb.WriteString(";")
+  }
+  return sb.String()
+}
+
+// Used internally. Returns a textual representation of the parameter definitions.
+func describeDefs(param *Parameter) string {
+  var sb strings.Builder
+  for _, d := range param.Definitions() {
+    fmt.Fprintf(&sb, "%s %v %d %v %v\n", d.Name(), d.Aliases(), d.NumArgs(), d.Types(), d.Metavars())
+  }
+  return sb.String()
+}
@@ -0,0 +1,173 @@
+package cmdargs
+
+import (
+  "bufio"
+  "fmt"
+  "io"
+  "strings"
+)
+
+// PositionalFunc is invoked by Parameter.EvaluateReader for every extra argument. name is the name of the positional
+// argument that receives value, or empty if no positional arguments are defined. value is converted to the declared
+// type of the positional argument.
+//
+// Returned errors abort the evaluation and are returned by EvaluateReader unchanged.
+type PositionalFunc func(name string, value Generic) error
+
+// Tokenizer for delimited command line arguments
+type tokenReader struct {
+  rd          *bufio.Reader   // Source of the arguments
+  delim       byte            // Delimiter between arguments
+  count       int             // Number of arguments read so far
+  pending     string          // Argument that has been put back
+  hasPending  bool            // Indicates whether "pending" is available
+}
+
+// EvaluateReader evaluates command line arguments that are read from rd and separated by delim, e.g. 0 for the
+// output of "find -print0" or '\n' for one argument per line. Empty arguments are skipped. If delim is a newline,
+// trailing carriage returns are removed. Arguments are evaluated as described for Evaluate, except that rd doesn't
+// provide the application name. Positions in the command line arguments list count the first argument in rd as 1.
+//
+// If fn is nil, extra arguments are collected as described for Evaluate. Otherwise they are not stored but passed to
+// fn as soon as they are read, which keeps the memory usage bounded for input of any size. In this case, values are
+// assigned to the positional arguments as if preceding positional arguments receive their maximum number of values,
+// and missing values are reported after the input has been read completely. Validation functions registered by
+// AddCheck are invoked after the last call to fn.
+//
+// Help and version options are only considered if they appear before the first extra argument or subcommand.
+// Dynamic completion is not available.
+func (param *Parameter) EvaluateReader(rd io.Reader, delim byte, fn PositionalFunc) error {
+  r, err := param.ParseReader(rd, delim, fn)
+  r.store()
+  return err
+}
+
+// ParseReader behaves just like EvaluateReader, but returns the evaluated arguments as a Result instead of storing
+// them in the Parameter structure. See Parse for details.
+func (param *Parameter) ParseReader(rd io.Reader, delim byte, fn PositionalFunc) (*Result, error) {
+  tr := &tokenReader{rd: bufio.NewReader(rd), delim: delim}
+  return param.parseStream(tr, []string{""}, fn, nil)
+}
+
+
+// Used internally. Evaluates the arguments provided by tr. args contains the arguments that were read before,
+// followed by the application or subcommand name. parent is the result of the parent command if param is a
+// subcommand, or nil otherwise.
+func (param *Parameter) parseStream(tr *tokenReader, args []string, fn PositionalFunc,
+                                    parent *Result) (*Result, error) {
+  r := newResult(param)
+  r.parent = parent
+  start := len(args) - 1
+  args, err := tr.readOptions(param, args)
+  if err != nil { return r, err }
+  r.reserve(args[start:])
+  r.self = args[start]
+
+  // parsing options
+  if _, err = r.evalOptions(args, start + 1); err != nil { return r, err }
+
+  // initializing subcommand
+  token, ok, err := tr.next()
+  if err != nil { return r, err }
+  if ok {
+    r.command = param.getCommand(token)
+  }
+
+  if def := r.getSpecial(); def != nil { return r, param.handleSpecial(def, r) }
+
+  if r.command != nil {
+    if err = r.runChecks(); err != nil { return r, err }
+    r.sub, err = r.command.param.parseStream(tr, append(args, token), fn, r)
+    return r, err
+  }
+
+  if fn == nil {
+    // collecting extra arguments
+    r.extraPos = tr.count
+    for ; ok; token, ok, err = tr.next() {
+      r.extra = append(r.extra, Generic(String(token)))
+      r.rawExtra = append(r.rawExtra, token)
+    }
+    if err != nil { return r, err }
+    if len(param.positionals) > 0 {
+      if err = r.evalPositionals(r.extraPos); err != nil { return r, err }
+    }
+    return r, r.runChecks()
+  }
+
+  // passing extra arguments to fn
+  counts := make(map[string]int, len(param.positionals))
+  for idx := 0; ok; idx++ {
+    name, value := "", Generic(String(token))
+    if len(param.positionals) > 0 {
+      p := param.getPositionalAt(idx)
+      if p == nil { return r, &EvalError{Kind: UnexpectedArgument, Index: -1, Position: tr.count, Value: token} }
+      if value, err = p.convert(value, tr.count); err != nil { return r, err }
+      name = p.name
+      counts[name]++
+    }
+    if err = fn(name, value); err != nil { return r, err }
+    if token, ok, err = tr.next(); err != nil { return r, err }
+  }
+
+  for _, p := range param.positionals {
+    if counts[p.name] < p.minArgs {
+      return r, &EvalError{Kind: MissingArgument, Positional: p.name, Index: -1, Position: -1, Value: p.getMetavar(),
+                           Err: fmt.Errorf("available=%d, need=%d", counts[p.name], p.minArgs)}
+    }
+  }
+  return r, r.runChecks()
+}
+
+// Used internally. Reads the leading options and their arguments and appends them to args. The first argument that
+// is not an option is put back.
+func (tr *tokenReader) readOptions(param *Parameter, args []string) ([]string, error) {
+  for {
+    token, ok, err := tr.next()
+    if err != nil || !ok { return args, err }
+    if !isOption(token) {
+      tr.unread(token)
+      return args, nil
+    }
+    args = append(args, token)
+
+    // unrecognized options are reported by the evaluation
+    name, _, hasValue := strings.Cut(token, "=")
+    def, found := param.aliases[getOptionName(name)]
+    if !found { return args, nil }
+
+    numArgs := def.numArgs
+    if numArgs > 0 && hasValue { numArgs-- }
+    for ; numArgs > 0; numArgs-- {
+      if token, ok, err = tr.next(); err != nil || !ok { return args, err }
+      args = append(args, token)
+    }
+  }
+}
+
+// Used internally. Returns the next non-empty argument. The second return value is false if no more arguments are
+// available.
+func (tr *tokenReader) next() (token string, ok bool, err error) {
+  if tr.hasPending {
+    tr.hasPending = false
+    return tr.pending, true, nil
+  }
+  for {
+    token, err = tr.rd.ReadString(tr.delim)
+    eof := (err == io.EOF)
+    if err != nil && !eof { return "", false, err }
+    if !eof { token = token[:len(token) - 1] }
+    if tr.delim == '\n' { token = strings.TrimSuffix(token, "\r") }
+    if len(token) > 0 {
+      tr.count++
+      return token, true, nil
+    }
+    if eof { return "", false, nil }
+  }
+}
+
+// Used internally. Puts back the given argument, which is returned by the next call of next.
+func (tr *tokenReader) unread(token string) {
+  tr.pending = token
+  tr.hasPending = true
+}
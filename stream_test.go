@@ -0,0 +1,135 @@
+package cmdargs
+
+import (
+  "errors"
+  "fmt"
+  "strings"
+  "testing"
+)
+
+// Used internally. Returns parameter definitions for the stream tests.
+func newStreamParam() (param, build *Parameter) {
+  param = Create()
+  param.AddParameter("verbose", []string{"v"}, 0)
+  param.AddParameter("size", nil, 2)
+  param.SetParameterTypes("size", TypeInt, TypeInt)
+  build = Create()
+  build.AddParameter("force", []string{"f"}, 0)
+  param.AddCommand("build", build)
+  return
+}
+
+func TestEvaluateReaderDelimiters(t *testing.T) {
+  for _, c := range []struct{ input string; delim byte }{
+    {"-v\x00--size\x00640\x00480\x00a b\x00\x00c\x00", 0},
+    {"-v\n--size\n640\n480\na b\n\nc", '\n'},
+    {"-v\r\n--size\r\n640\r\n480\r\na b\r\n\r\nc\r\n", '\n'},
+  } {
+    param, _ := newStreamParam()
+    if err := param.EvaluateReader(strings.NewReader(c.input), c.delim, nil); err != nil {
+      t.Fatalf("EvaluateReader(%q): %v", c.input, err)
+    }
+    arg, _ := param.GetArgAt(1)
+    if !param.GetArgExists("v") || arg.Position != 2 || fmt.Sprint(arg.Arguments) != "[640 480]" {
+      t.Errorf("EvaluateReader(%q): option = %+v", c.input, arg)
+    }
+    if param.GetArgExtraLength() != 2 || param.GetArgExtra(0).ToString() != "a b" ||
+       param.GetArgExtra(1).ToString() != "c" || param.GetArgExtraPosition(0) != 5 {
+      t.Errorf("EvaluateReader(%q): extra arguments = %v", c.input, param.GetArgParams(""))
+    }
+  }
+
+  // carriage returns are only removed for newline delimiters
+  param, _ := newStreamParam()
+  if err := param.EvaluateReader(strings.NewReader("a\r\x00"), 0, nil); err != nil {
+    t.Fatalf("EvaluateReader: %v", err)
+  }
+  if s := param.GetArgExtra(0).ToString(); s != "a\r" {
+    t.Errorf("GetArgExtra(0) = %q; want \"a\\r\"", s)
+  }
+
+  // option arguments missing at the end of the input
+  err := param.EvaluateReader(strings.NewReader("--size\n640\n"), '\n', nil)
+  var e *EvalError
+  if !errors.As(err, &e) || e.Kind != MissingValue || e.Option != "size" || e.Index != 1 {
+    t.Errorf("EvaluateReader = %v; want missing value of size", err)
+  }
+}
+
+func TestEvaluateReaderCallback(t *testing.T) {
+  param := Create()
+  param.AddParameter("verbose", []string{"v"}, 0)
+  param.AddPositional("src", 1, 2)
+  param.AddPositional("dst", 1, 1)
+  param.SetPositionalType("dst", TypeInt)
+
+  var got []string
+  fn := func(name string, value Generic) error {
+    got = append(got, fmt.Sprintf("%s=%v", name, value))
+    return nil
+  }
+  if err := param.EvaluateReader(strings.NewReader("-v\na\nb\n7\n"), '\n', fn); err != nil {
+    t.Fatalf("EvaluateReader: %v", err)
+  }
+  if s := strings.Join(got, " "); s != "src=a src=b dst=7" {
+    t.Errorf("fn received %q; want \"src=a src=b dst=7\"", s)
+  }
+  if !param.GetArgExists("v") || param.GetArgExtraLength() != 0 {
+    t.Error("options are missing or extra arguments are stored")
+  }
+
+  // missing positional arguments are reported after the input has been read
+  got = nil
+  err := param.EvaluateReader(strings.NewReader("a\n"), '\n', fn)
+  var e *EvalError
+  if !errors.As(err, &e) || e.Kind != MissingArgument || e.Positional != "dst" {
+    t.Errorf("EvaluateReader = %v; want missing argument dst", err)
+  }
+  if len(got) != 1 {
+    t.Errorf("fn received %q; want 1 value", got)
+  }
+
+  // conversion errors and errors returned by fn abort the evaluation
+  err = param.EvaluateReader(strings.NewReader("a\nb\nx\n"), '\n', fn)
+  if !errors.As(err, &e) || e.Kind != InvalidValue || e.Positional != "dst" || e.Position != 3 {
+    t.Errorf("EvaluateReader = %v; want invalid value of dst at position 3", err)
+  }
+  errStop := errors.New("stop")
+  err = param.EvaluateReader(strings.NewReader("a\nb\n"), '\n', func(string, Generic) error { return errStop })
+  if err != errStop {
+    t.Errorf("EvaluateReader = %v; want error of fn", err)
+  }
+  err = param.EvaluateReader(strings.NewReader("a\nb\n1\n2\n"), '\n', fn)
+  if !errors.As(err, &e) || e.Kind != UnexpectedArgument || e.Value != "2" {
+    t.Errorf("EvaluateReader = %v; want unexpected argument", err)
+  }
+}
+
+func TestEvaluateReaderCommand(t *testing.T) {
+  param, build := newStreamParam()
+  input := "-v\x00build\x00-f\x00src\x00doc\x00"
+  if err := param.EvaluateReader(strings.NewReader(input), 0, nil); err != nil {
+    t.Fatalf("EvaluateReader: %v", err)
+  }
+  if name, cmd := param.GetCommand(); name != "build" || cmd != build {
+    t.Fatalf("GetCommand = %q; want build", name)
+  }
+  if !param.GetArgExists("v") || param.GetArgExists("f") || param.GetArgExtraLength() != 0 {
+    t.Error("parent command evaluated arguments of the subcommand")
+  }
+  arg, _ := build.GetArgAt(0)
+  if build.GetArgSelf() != "build" || arg.Name != "force" || arg.Position != 3 || build.GetArgExtraLength() != 2 ||
+     build.GetArgExtra(1).ToString() != "doc" || build.GetArgExtraPosition(0) != 4 {
+    t.Errorf("subcommand results = %q, %+v, %v", build.GetArgSelf(), arg, build.GetArgParams(""))
+  }
+
+  // callback mode passes the extra arguments of the subcommand
+  var got []string
+  fn := func(name string, value Generic) error { got = append(got, value.ToString()); return nil }
+  if err := param.EvaluateReader(strings.NewReader(input), 0, fn); err != nil {
+    t.Fatalf("EvaluateReader: %v", err)
+  }
+  if s := strings.Join(got, " "); s != "src doc" || !build.GetArgExists("f") {
+    t.Errorf("fn received %q; want \"src doc\"", s)
+  }
+}
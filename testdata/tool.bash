@@ -0,0 +1,52 @@
+# bash completion for tool
+_tool_complete() {
+  local cur="${COMP_WORDS[COMP_CWORD]}"
+  local cpath='tool' opt='' need=0 argi=0 pos=0 ended=0 i w n
+  [[ $cur == = ]] && cur=''
+  for ((i = 1; i < COMP_CWORD; i++)); do
+    w="${COMP_WORDS[i]}"
+    [[ $w == = ]] && continue
+    if ((need > 0)); then need=$((need - 1)); argi=$((argi + 1)); continue; fi
+    if ((ended)); then pos=$((pos + 1)); continue; fi
+    if [[ $w == -?* ]]; then
+      n="${w%%=*}"; n="${n#-}"; n="${n#-}"
+      opt="$cpath:$n" need=0 argi=0
+      case "$opt" in
+        'tool:jobs'|'tool:j') opt='tool:jobs' need=1 ;;
+        'tool:color') opt='tool:color' need=1 ;;
+        'tool build:target') opt='tool build:target' need=1 ;;
+      esac
+      [[ $w == *=* ]] && ((need > 0)) && need=$((need - 1)) && argi=1
+    else
+      case "$cpath:$w" in
+        'tool:build') cpath='tool build' ;;
+        *) ended=1 pos=1 ;;
+      esac
+    fi
+  done
+  if ((need > 0)); then
+    case "$opt:$argi" in
+      'tool:jobs:0') COMPREPLY=() ;;
+      'tool:color:0') COMPREPLY=($(compgen -W 'auto always never' -- "$cur")) ;;
+      *) COMPREPLY=($(compgen -f -- "$cur")) ;;
+    esac
+    return 0
+  fi
+  if ((ended)); then COMPREPLY=($(compgen -f -- "$cur")); return 0; fi
+  case "$cpath" in
+    'tool')
+      if [[ $cur == -* ]]; then
+        COMPREPLY=($(compgen -W '-V --version -h --help --build -j --jobs --color -q --quiet' -- "$cur"))
+      else
+        COMPREPLY=($(compgen -W 'build' -- "$cur"))
+      fi ;;
+    'tool build')
+      if [[ $cur == -* ]]; then
+        COMPREPLY=($(compgen -W '-f --force --target' -- "$cur"))
+      else
+        COMPREPLY=($(compgen -f -- "$cur"))
+      fi ;;
+  esac
+  return 0
+}
+complete -F _tool_complete 'tool'
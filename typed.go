@@ -0,0 +1,139 @@
+package cmdargs
+
+import (
+  "fmt"
+)
+
+// Option is a type-safe handle to a parameter definition. It is returned by the functions Int, Uint, Float, Bool
+// and Str, which register the parameter in the given Parameter structure.
+//
+// Values of the option are available after a successful call to Parameter.Evaluate, or from the Result returned by
+// Parameter.Parse. Arguments that cannot be converted to the type of the option are reported as InvalidValue errors
+// by Evaluate.
+type Option[T any] struct {
+  param     *Parameter
+  ref       *paramType
+  def       T
+  conv      func(Generic) (T, bool)
+}
+
+// Int registers a parameter with a single signed integer argument and returns a typed handle to it.
+//
+// name and aliases are treated as in Parameter.AddParameter. def is returned by Value if the option was not
+// specified at the command line.
+func Int(param *Parameter, name string, aliases []string, def int64) *Option[int64] {
+  return newOption(param, name, aliases, 1, TypeInt, def, Generic.Int)
+}
+
+// Uint registers a parameter with a single unsigned integer argument and returns a typed handle to it.
+//
+// name and aliases are treated as in Parameter.AddParameter. def is returned by Value if the option was not
+// specified at the command line.
+func Uint(param *Parameter, name string, aliases []string, def uint64) *Option[uint64] {
+  return newOption(param, name, aliases, 1, TypeUint, def, Generic.Uint)
+}
+
+// Float registers a parameter with a single floating point argument and returns a typed handle to it.
+//
+// name and aliases are treated as in Parameter.AddParameter. def is returned by Value if the option was not
+// specified at the command line.
+func Float(param *Parameter, name string, aliases []string, def float64) *Option[float64] {
+  return newOption(param, name, aliases, 1, TypeFloat, def, Generic.Float)
+}
+
+// Str registers a parameter with a single string argument and returns a typed handle to it.
+// (The name String is already taken by the underlying type of Generic.)
+//
+// name and aliases are treated as in Parameter.AddParameter. def is returned by Value if the option was not
+// specified at the command line.
+func Str(param *Parameter, name string, aliases []string, def string) *Option[string] {
+  return newOption(param, name, aliases, 1, TypeString, def, Generic.String)
+}
+
+// Bool registers a parameter without arguments and returns a typed handle to it. Value returns true if the option
+// was specified at the command line, and def otherwise.
+//
+// name and aliases are treated as in Parameter.AddParameter.
+func Bool(param *Parameter, name string, aliases []string, def bool) *Option[bool] {
+  return newOption(param, name, aliases, 0, TypeBool, def, func(Generic) (bool, bool) { return true, true })
+}
+
+// Name returns the normalized long name of the option.
+func (o *Option[T]) Name() string {
+  return o.ref.name
+}
+
+// Default returns the default value of the option.
+func (o *Option[T]) Default() T {
+  return o.def
+}
+
+// Exists returns whether the option has been specified at the command line.
+func (o *Option[T]) Exists() bool {
+  return o.ExistsIn(o.param.getResult())
+}
+
+// Value returns the value of the last occurrence of the option, or the default value if the option was not
+// specified at the command line.
+func (o *Option[T]) Value() T {
+  return o.ValueOf(o.param.getResult())
+}
+
+// Values returns the values of all occurrences of the option in the order they were specified at the command line.
+// Returns an empty list if the option was not specified.
+func (o *Option[T]) Values() []T {
+  return o.ValuesOf(o.param.getResult())
+}
+
+// ExistsIn behaves just like Exists, but considers the given Result.
+func (o *Option[T]) ExistsIn(r *Result) bool {
+  return len(r.index[o.ref.name]) > 0
+}
+
+// ValueOf behaves just like Value, but considers the given Result.
+func (o *Option[T]) ValueOf(r *Result) T {
+  if positions := r.index[o.ref.name]; len(positions) > 0 {
+    if arg, err := r.GetArgAt(positions[len(positions) - 1]); err == nil {
+      return o.get(arg)
+    }
+  }
+  return o.def
+}
+
+// ValuesOf behaves just like Values, but considers the given Result.
+func (o *Option[T]) ValuesOf(r *Result) []T {
+  retVal := make([]T, 0)
+  for _, pos := range r.index[o.ref.name] {
+    retVal = append(retVal, o.get(Argument{Name: o.ref.name, Arguments: r.options[pos].value}))
+  }
+  return retVal
+}
+
+
+// Used internally. Registers the parameter and creates the typed handle.
+func newOption[T any](param *Parameter, name string, aliases []string, numArgs int, vtype ValueType, def T,
+                      conv func(Generic) (T, bool)) *Option[T] {
+  param.AddParameter(name, aliases, numArgs)
+  o := &Option[T]{param: param, def: def, conv: conv}
+  // the handle follows the definition if it is renamed
+  if o.ref = param.aliases[getOptionName(name)]; o.ref == nil {
+    o.ref = &paramType{name: getOptionName(name)}
+  }
+  if numArgs > 0 {
+    param.SetParameterTypes(o.ref.name, vtype)
+    if s := fmt.Sprint(def); len(s) > 0 {
+      param.SetParameterDefault(o.ref.name, s)
+    }
+  }
+  return o
+}
+
+// Used internally. Converts the argument of the given option instance.
+func (o *Option[T]) get(arg Argument) T {
+  var v Generic = String("")
+  if len(arg.Arguments) > 0 {
+    v = arg.Arguments[0]
+  }
+  ret, _ := o.conv(v)
+  return ret
+}
@@ -0,0 +1,386 @@
+package cmdargs
+
+import (
+  "io"
+  "os"
+  "path/filepath"
+  "strconv"
+  "strings"
+)
+
+const (
+  defaultWidth  = 80  // Default line width of the usage output
+  minWidth      = 40  // Minimum line width of the usage output
+  maxNameWidth  = 30  // Maximum width of the option column in the usage output
+)
+
+// SetProgramName defines the application name shown in the usage output. If no name is defined, the application
+// name evaluated by Evaluate or the base name of os.Args[0] is used. Subcommands are shown with the application name
+// of the parent command.
+func (param *Parameter) SetProgramName(name string) {
+  param.progName = name
+}
+
+// SetProgramDescription defines a short description of the application for the usage output.
+func (param *Parameter) SetProgramDescription(desc string) {
+  param.progDesc = desc
+}
+
+// SetUsageWidth defines the maximum line width of the usage output. Specify 0 to autodetect the width from the
+// COLUMNS environment variable, which falls back to 80 characters.
+func (param *Parameter) SetUsageWidth(width int) {
+  if width < 0 { width = 0 }
+  param.width = width
+}
+
+// SetParameterDescription defines the description of the parameter specified by "name" for the usage output.
+//
+// Returns whether a parameter definition of given name exists.
+func (param *Parameter) SetParameterDescription(name string, desc string) bool {
+  p, ok := param.aliases[getOptionName(name)]
+  if ok {
+    p.desc = desc
+  }
+  return ok
+}
+
+// SetParameterMetavars defines the placeholder names of the option arguments of the parameter specified by "name",
+// e.g. "X" and "Y" for "--position X Y". Arguments without placeholder name are shown as the upper-cased parameter
+// name.
+//
+// Placeholder names also identify the option arguments in error messages, and they can be used to look up option
+// arguments by Argument.Value or GetArgSlot.
+//
+// Returns whether a parameter definition of given name exists.
+func (param *Parameter) SetParameterMetavars(name string, metavars ...string) bool {
+  p, ok := param.aliases[getOptionName(name)]
+  if ok {
+    p.metavars = append([]string(nil), metavars...)
+  }
+  return ok
+}
+
+// SetParameterGroup assigns the parameter specified by "name" to a section of the usage output. Parameters without
+// group are listed in the "Options" section.
+//
+// Returns whether a parameter definition of given name exists.
+func (param *Parameter) SetParameterGroup(name string, group string) bool {
+  p, ok := param.aliases[getOptionName(name)]
+  if ok {
+    p.group = group
+  }
+  return ok
+}
+
+// SetParameterDefault defines the default values of the option arguments of the parameter specified by "name" for
+// the usage output.
+//
+// Returns whether a parameter definition of given name exists.
+func (param *Parameter) SetParameterDefault(name string, values ...string) bool {
+  p, ok := param.aliases[getOptionName(name)]
+  if ok {
+    p.defValue = append([]string(nil), values...)
+  }
+  return ok
+}
+
+// WriteUsage writes a usage description generated from the parameter definitions to w. The output consists of a
+// synopsis, the application description, a table of all options, grouped by sections, and tables of the positional
+// arguments and subcommands.
+func (param *Parameter) WriteUsage(w io.Writer) error {
+  return param.writeUsage(w, param.getProgramName())
+}
+
+// Used internally. Writes the usage description for the given application name.
+func (param *Parameter) writeUsage(w io.Writer, prog string) error {
+  width := param.getWidth()
+  var sb strings.Builder
+
+  // synopsis
+  writeWrapped(&sb, "Usage: " + prog, param.getSynopsis(), width)
+
+  if len(param.progDesc) > 0 {
+    sb.WriteString("\n")
+    for _, line := range wrapText(param.progDesc, width) {
+      sb.WriteString(line)
+      sb.WriteString("\n")
+    }
+  }
+
+  // option table
+  nameWidth := 0
+  for _, def := range param.defs {
+    if n := len(def.getUsageName()); n > nameWidth && n <= maxNameWidth {
+      nameWidth = n
+    }
+  }
+  for _, group := range param.getGroups() {
+    if !param.hasGroup(group) { continue }
+    sb.WriteString("\n")
+    if len(group) > 0 {
+      sb.WriteString(group + ":\n")
+    } else {
+      sb.WriteString("Options:\n")
+    }
+    for _, def := range param.defs {
+      if def.group == group {
+        writeOption(&sb, def.getUsageName(), def.getUsageText(), nameWidth, width)
+      }
+    }
+  }
+
+  if len(param.positionals) > 0 {
+    sb.WriteString("\nArguments:\n")
+    posWidth := 0
+    for _, p := range param.positionals {
+      if n := len(p.getMetavar()); n > posWidth && n <= maxNameWidth { posWidth = n }
+    }
+    for _, p := range param.positionals {
+      writeOption(&sb, p.getMetavar(), p.getUsageText(), posWidth, width)
+    }
+  }
+
+  if len(param.commands) > 0 {
+    sb.WriteString("\nCommands:\n")
+    cmdWidth := 0
+    for _, c := range param.commands {
+      if len(c.name) > cmdWidth && len(c.name) <= maxNameWidth { cmdWidth = len(c.name) }
+    }
+    for _, c := range param.commands {
+      writeOption(&sb, c.name, c.param.progDesc, cmdWidth, width)
+    }
+  }
+
+  _, err := io.WriteString(w, sb.String())
+  return err
+}
+
+// Used internally. Returns the elements of the synopsis, excluding the application name.
+func (param *Parameter) getSynopsis() []string {
+  items := make([]string, 0, len(param.defs) + 1)
+  for _, def := range param.defs {
+    item := strings.Join(append([]string{getPrefixedName(def.name)}, def.getMetavars()...), " ")
+    items = append(items, "[" + item + "]")
+  }
+  for _, p := range param.positionals {
+    if item := p.getSynopsis(); len(item) > 0 {
+      items = append(items, item)
+    }
+  }
+  if len(param.commands) > 0 {
+    items = append(items, "COMMAND [ARGS...]")
+  }
+  return items
+}
+
+// Used internally. Returns the line width for the usage output.
+func (param *Parameter) getWidth() int {
+  width := param.width
+  if width == 0 {
+    width = defaultWidth
+    if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+      width = n
+    }
+  }
+  if width < minWidth { width = minWidth }
+  return width
+}
+
+// Used internally. Returns the application name for the usage output.
+func (param *Parameter) getProgramName() string {
+  return param.getProgramNameFor(param.getResult())
+}
+
+// Used internally. Returns the application name for the usage output, based on the given evaluated arguments.
+// Subcommands consider the evaluated arguments of their parent commands.
+func (param *Parameter) getProgramNameFor(r *Result) string {
+  if len(param.progName) > 0 { return param.progName }
+  if param.parent != nil {
+    if r.parent != nil { return param.parent.getProgramNameFor(r.parent) + " " + param.cmdName }
+    return param.parent.getProgramName() + " " + param.cmdName
+  }
+  if len(r.self) > 0 { return filepath.Base(r.self) }
+  if len(os.Args) > 0 { return filepath.Base(os.Args[0]) }
+  return ""
+}
+
+// Used internally. Returns the list of parameter groups in order of first appearance. The unnamed group is always
+// listed first.
+func (param *Parameter) getGroups() []string {
+  retVal := []string{""}
+  for _, def := range param.defs {
+    found := false
+    for _, g := range retVal {
+      if g == def.group { found = true; break }
+    }
+    if !found {
+      retVal = append(retVal, def.group)
+    }
+  }
+  return retVal
+}
+
+// Used internally. Returns whether any parameter is assigned to the given group.
+func (param *Parameter) hasGroup(group string) bool {
+  for _, def := range param.defs {
+    if def.group == group { return true }
+  }
+  return false
+}
+
+// Used internally. Returns the placeholder names of all option arguments.
+func (def *paramType) getMetavars() []string {
+  retVal := make([]string, def.numArgs)
+  for i := range retVal {
+    retVal[i] = def.getMetavar(i)
+  }
+  return retVal
+}
+
+// Used internally. Returns the placeholder name of the option argument at the specified index.
+func (def *paramType) getMetavar(index int) string {
+  if index >= 0 && index < len(def.metavars) && len(def.metavars[index]) > 0 {
+    return def.metavars[index]
+  }
+  return strings.ToUpper(strings.ReplaceAll(def.name, "-", "_"))
+}
+
+// Used internally. Returns all option names with prefix, single character names first.
+func (def *paramType) getNames() []string {
+  names := make([]string, 0, len(def.aliases) + 1)
+  for _, a := range append([]string{def.name}, def.aliases...) {
+    if len(a) == 1 { names = append(names, getPrefixedName(a)) }
+  }
+  for _, a := range append([]string{def.name}, def.aliases...) {
+    if len(a) > 1 { names = append(names, getPrefixedName(a)) }
+  }
+  return names
+}
+
+// Used internally. Returns all option names with prefix, followed by the placeholder names of the option arguments.
+func (def *paramType) getUsageName() string {
+  return strings.Join(append([]string{strings.Join(def.getNames(), ", ")}, def.getMetavars()...), " ")
+}
+
+// Used internally. Returns the option description, including choices, default values and environment variables.
+func (def *paramType) getUsageText() string {
+  s := def.desc
+  if choices := def.getChoices(-1); len(choices) > 0 {
+    s += " (choices: " + strings.Join(choices, ", ") + ")"
+  }
+  if len(def.defValue) > 0 {
+    s += " (default: " + strings.Join(def.defValue, " ") + ")"
+  }
+  if len(def.env) > 0 {
+    s += " (env: " + def.env + ")"
+  }
+  return strings.TrimSpace(s)
+}
+
+// Used internally. Returns the allowed choices for the option argument at the specified index as defined by
+// ChoiceValidator instances. A negative index returns the choices for all arguments. Returns nil if no choices are
+// defined.
+func (def *paramType) getChoices(index int) []string {
+  return getValidatorChoices(def.getValidators(index))
+}
+
+// Used internally. Returns the validators for the option argument at the specified index. A negative index returns
+// the validators for all arguments.
+func (def *paramType) getValidators(index int) []Validator {
+  var retVal []Validator
+  for _, e := range def.validators {
+    if e.index < 0 || index < 0 || e.index == index {
+      retVal = append(retVal, e.v)
+    }
+  }
+  return retVal
+}
+
+// Used internally. Returns the allowed choices as defined by the ChoiceValidator instances in the given list.
+// Returns nil if no choices are defined.
+func getValidatorChoices(validators []Validator) []string {
+  var retVal []string
+  for _, v := range validators {
+    if v, ok := v.(*ChoiceValidator); ok {
+      retVal = append(retVal, v.Choices...)
+    }
+  }
+  return retVal
+}
+
+// Used internally. Adds the conventional prefix to the given normalized option name, i.e. a single hyphen for single
+// character names and two hyphens otherwise.
+func getPrefixedName(name string) string {
+  if len(name) == 1 { return "-" + name }
+  return "--" + name
+}
+
+// Used internally. Writes a single option entry of the option table.
+func writeOption(sb *strings.Builder, name, text string, nameWidth, width int) {
+  indent := 2 + nameWidth + 2
+  sb.WriteString("  ")
+  sb.WriteString(name)
+  lines := wrapText(text, width - indent)
+  if len(lines) == 0 {
+    sb.WriteString("\n")
+    return
+  }
+  if len(name) > nameWidth {
+    sb.WriteString("\n")
+    sb.WriteString(strings.Repeat(" ", indent))
+  } else {
+    sb.WriteString(strings.Repeat(" ", indent - 2 - len(name)))
+  }
+  for i, line := range lines {
+    if i > 0 { sb.WriteString(strings.Repeat(" ", indent)) }
+    sb.WriteString(line)
+    sb.WriteString("\n")
+  }
+}
+
+// Used internally. Writes prefix followed by the given items, separated by spaces. Lines are wrapped at the given
+// width and indented by the length of prefix.
+func writeWrapped(sb *strings.Builder, prefix string, items []string, width int) {
+  indent := strings.Repeat(" ", len(prefix) + 1)
+  sb.WriteString(prefix)
+  pos := len(prefix)
+  for i, item := range items {
+    if i > 0 && pos + 1 + len(item) > width {
+      sb.WriteString("\n")
+      sb.WriteString(indent)
+      pos = len(indent)
+    } else {
+      sb.WriteString(" ")
+      pos++
+    }
+    sb.WriteString(item)
+    pos += len(item)
+  }
+  sb.WriteString("\n")
+}
+
+// Used internally. Splits text into lines of the given maximum width. Words are never split. Line breaks in text
+// are preserved.
+func wrapText(text string, width int) []string {
+  var retVal []string
+  if width < 1 { width = 1 }
+  for _, paragraph := range strings.Split(text, "\n") {
+    line := ""
+    for _, word := range strings.Fields(paragraph) {
+      if len(line) > 0 && len(line) + 1 + len(word) > width {
+        retVal = append(retVal, line)
+        line = ""
+      }
+      if len(line) > 0 { line += " " }
+      line += word
+    }
+    if len(line) > 0 || len(strings.TrimSpace(paragraph)) == 0 {
+      retVal = append(retVal, line)
+    }
+  }
+  // trailing empty lines are not needed
+  for len(retVal) > 0 && len(retVal[len(retVal) - 1]) == 0 {
+    retVal = retVal[:len(retVal) - 1]
+  }
+  return retVal
+}
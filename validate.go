@@ -0,0 +1,203 @@
+package cmdargs
+
+import (
+  "fmt"
+  "os"
+  "regexp"
+  "strings"
+)
+
+// Validator checks a single option argument. Validators are attached to parameter definitions by
+// Parameter.AddValidator or Parameter.AddArgValidator and are invoked by Evaluate.
+//
+// The returned error should describe the requirement that is not satisfied. Evaluate wraps it in an *EvalError of
+// kind InvalidValue which also names the option and the offending argument.
+type Validator interface {
+  Validate(value Generic) error
+}
+
+// ValidatorFunc is an adapter to allow the use of ordinary functions as validators.
+type ValidatorFunc func(value Generic) error
+
+// RangeValidator checks whether numeric arguments lie within a given range. Use Range, Min or Max to create it.
+type RangeValidator struct {
+  // Min defines the lower boundary. It is only checked if HasMin is true.
+  Min     float64
+  HasMin  bool
+  // Max defines the upper boundary. It is only checked if HasMax is true.
+  Max     float64
+  HasMax  bool
+}
+
+// ChoiceValidator checks whether arguments match one of a fixed set of strings. Use Choices or ChoicesFold to
+// create it.
+type ChoiceValidator struct {
+  // Choices contains the list of allowed arguments.
+  Choices     []string
+  // IgnoreCase indicates whether arguments are compared case-insensitively.
+  IgnoreCase  bool
+}
+
+// PatternValidator checks whether arguments match a regular expression. Use Match to create it.
+type PatternValidator struct {
+  Pattern *regexp.Regexp
+}
+
+// PathValidator checks whether arguments refer to existing paths. Use PathExists or DirExists to create it.
+type PathValidator struct {
+  // DirOnly indicates whether the path must refer to a directory.
+  DirOnly bool
+}
+
+// Validate calls f(value).
+func (f ValidatorFunc) Validate(value Generic) error {
+  return f(value)
+}
+
+
+// Func returns a Validator that invokes the given function.
+func Func(fn func(value Generic) error) Validator {
+  return ValidatorFunc(fn)
+}
+
+// Range returns a validator that accepts numeric arguments in the range [min, max].
+func Range(min, max float64) *RangeValidator {
+  return &RangeValidator{Min: min, HasMin: true, Max: max, HasMax: true}
+}
+
+// Min returns a validator that accepts numeric arguments greater than or equal to min.
+func Min(min float64) *RangeValidator {
+  return &RangeValidator{Min: min, HasMin: true}
+}
+
+// Max returns a validator that accepts numeric arguments less than or equal to max.
+func Max(max float64) *RangeValidator {
+  return &RangeValidator{Max: max, HasMax: true}
+}
+
+// Validate checks whether value is a number within the defined range.
+func (v *RangeValidator) Validate(value Generic) error {
+  f, ok := value.Float()
+  if !ok { return fmt.Errorf("expected number") }
+  if v.HasMin && v.HasMax && (f < v.Min || f > v.Max) {
+    return fmt.Errorf("must be in range [%v, %v]", v.Min, v.Max)
+  }
+  if v.HasMin && f < v.Min { return fmt.Errorf("must be at least %v", v.Min) }
+  if v.HasMax && f > v.Max { return fmt.Errorf("must be at most %v", v.Max) }
+  return nil
+}
+
+
+// Choices returns a validator that accepts only the given strings. Comparison is case-sensitive.
+func Choices(choices ...string) *ChoiceValidator {
+  return &ChoiceValidator{Choices: append([]string(nil), choices...)}
+}
+
+// ChoicesFold returns a validator that accepts only the given strings. Comparison is case-insensitive.
+func ChoicesFold(choices ...string) *ChoiceValidator {
+  return &ChoiceValidator{Choices: append([]string(nil), choices...), IgnoreCase: true}
+}
+
+// Validate checks whether value matches one of the allowed choices.
+func (v *ChoiceValidator) Validate(value Generic) error {
+  s := value.ToString()
+  for _, c := range v.Choices {
+    if s == c || (v.IgnoreCase && strings.EqualFold(s, c)) {
+      return nil
+    }
+  }
+  return fmt.Errorf("must be one of %s", quoteList(v.Choices))
+}
+
+
+// Match returns a validator that accepts only arguments matching the given regular expression.
+// It panics if the expression cannot be parsed.
+func Match(pattern string) *PatternValidator {
+  return &PatternValidator{Pattern: regexp.MustCompile(pattern)}
+}
+
+// Validate checks whether value matches the regular expression.
+func (v *PatternValidator) Validate(value Generic) error {
+  if !v.Pattern.MatchString(value.ToString()) {
+    return fmt.Errorf("must match pattern \"%s\"", v.Pattern.String())
+  }
+  return nil
+}
+
+
+// PathExists returns a validator that accepts only paths to existing files or directories.
+func PathExists() *PathValidator {
+  return &PathValidator{}
+}
+
+// DirExists returns a validator that accepts only paths to existing directories.
+func DirExists() *PathValidator {
+  return &PathValidator{DirOnly: true}
+}
+
+// Validate checks whether value refers to an existing path.
+func (v *PathValidator) Validate(value Generic) error {
+  fi, err := os.Stat(value.ToString())
+  if err != nil { return fmt.Errorf("path does not exist") }
+  if v.DirOnly && !fi.IsDir() { return fmt.Errorf("not a directory") }
+  return nil
+}
+
+
+// AddValidator attaches the given validators to all arguments of the parameter specified by "name".
+//
+// Validators are invoked by Evaluate in the order they were added, after the argument has been converted to the
+// declared type.
+//
+// Returns whether a parameter definition of given name exists.
+func (param *Parameter) AddValidator(name string, validators ...Validator) bool {
+  return param.AddArgValidator(name, -1, validators...)
+}
+
+// AddArgValidator attaches the given validators to the argument at the specified index of the parameter specified
+// by "name". A negative index applies the validators to all arguments.
+//
+// Returns whether a parameter definition of given name exists.
+func (param *Parameter) AddArgValidator(name string, index int, validators ...Validator) bool {
+  p, ok := param.aliases[getOptionName(name)]
+  if ok {
+    if index < 0 { index = -1 }
+    for _, v := range validators {
+      if v != nil {
+        p.validators = append(p.validators, validatorEntry{index: index, v: v})
+      }
+    }
+  }
+  return ok
+}
+
+
+// Used internally. Associates a validator with an argument index.
+type validatorEntry struct {
+  index int           // Argument index, or -1 for all arguments
+  v     Validator
+}
+
+// Used internally. Applies all matching validators to the option argument at the given index.
+func (def *paramType) validate(index int, value Generic) error {
+  for _, e := range def.validators {
+    if e.index < 0 || e.index == index {
+      if err := e.v.Validate(value); err != nil {
+        return err
+      }
+    }
+  }
+  return nil
+}
+
+// Used internally. Returns a comma-separated list of double-quoted strings.
+func quoteList(list []string) string {
+  var sb strings.Builder
+  for i, s := range list {
+    if i > 0 { sb.WriteString(", ") }
+    sb.WriteString("\"")
+    sb.WriteString(s)
+    sb.WriteString("\"")
+  }
+  return sb.String()
+}